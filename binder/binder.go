@@ -5,6 +5,8 @@ import (
 	"github.com/xeipuuv/gojsonpointer"
 	"gitlab.com/tslocum/cview"
 	"manala/models"
+	"strconv"
+	"strings"
 )
 
 func NewRecipeFormBinder(rec models.RecipeInterface) (*RecipeFormBinder, error) {
@@ -27,27 +29,13 @@ func NewRecipeFormBinder(rec models.RecipeInterface) (*RecipeFormBinder, error)
 			item.SetLabel(option.Label)
 
 			// Item options
-			values, ok := option.Schema["enum"].([]interface{})
-			if !ok {
-				return nil, fmt.Errorf("invalid recipe option enum type: " + option.Label)
-			}
-			if len(values) == 0 {
-				return nil, fmt.Errorf("empty recipe option enum: " + option.Label)
+			values, err := recipeOptionEnumValues(option)
+			if err != nil {
+				return nil, err
 			}
 			for _, value := range values {
-				var text string
-				switch value {
-				case nil:
-					text = "<None>"
-				case true:
-					text = "<True>"
-				case false:
-					text = "<False>"
-				default:
-					text = fmt.Sprintf("%v", value)
-				}
 				itemValue := value
-				item.AddOption(text, func() {
+				item.AddOption(recipeOptionEnumText(value), func() {
 					bind.Value = itemValue
 				})
 			}
@@ -101,13 +89,7 @@ func (bndr *RecipeFormBinder) BindForm(form *cview.Form) {
 
 func (bndr *RecipeFormBinder) ApplyValues(values map[string]interface{}) error {
 	for _, bind := range bndr.binds {
-		// Json pointer
-		pointer, err := gojsonpointer.NewJsonPointer(bind.Option.Path)
-		if err != nil {
-			return err
-		}
-		_, err = pointer.Set(values, bind.Value)
-		if err != nil {
+		if err := applyValue(values, bind.Option.Path, bind.Value); err != nil {
 			return err
 		}
 	}
@@ -121,3 +103,130 @@ type recipeFormBind struct {
 	ItemIndex int
 	Value     interface{}
 }
+
+func NewRecipePromptBinder(rec models.RecipeInterface) (*RecipePromptBinder, error) {
+	bndr := &RecipePromptBinder{
+		recipe: &rec,
+	}
+
+	for _, option := range rec.Options() {
+
+		// Bind
+		bind := &recipePromptBind{
+			Option: option,
+		}
+
+		if _, ok := option.Schema["enum"]; ok {
+			// Choices based on enum schema
+			values, err := recipeOptionEnumValues(option)
+			if err != nil {
+				return nil, err
+			}
+			for _, value := range values {
+				bind.Choices = append(bind.Choices, &recipePromptChoice{
+					Text:  recipeOptionEnumText(value),
+					Value: value,
+				})
+			}
+
+			// First choice is the default one
+			bind.Value = bind.Choices[0].Value
+		} else if t, ok := option.Schema["type"]; ok && t == "string" {
+			// Free text based on string type
+			bind.Value = ""
+		} else {
+			return nil, fmt.Errorf("unable to bind recipe option into a prompt: " + option.Label)
+		}
+
+		bndr.binds = append(bndr.binds, bind)
+	}
+
+	return bndr, nil
+}
+
+type RecipePromptBinder struct {
+	recipe *models.RecipeInterface
+	binds  []*recipePromptBind
+}
+
+func (bndr *RecipePromptBinder) Binds() []*recipePromptBind {
+	return bndr.binds
+}
+
+func (bndr *RecipePromptBinder) ApplyValues(values map[string]interface{}) error {
+	for _, bind := range bndr.binds {
+		if err := applyValue(values, bind.Option.Path, bind.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type recipePromptBind struct {
+	Option  models.RecipeOption
+	Choices []*recipePromptChoice
+	Value   interface{}
+}
+
+// Set bind value from a prompt answer.
+// Choices are answered by their (one based) number, an empty answer keeping the default one.
+func (bind *recipePromptBind) SetAnswer(answer string) error {
+	answer = strings.TrimSpace(answer)
+
+	if bind.Choices == nil {
+		bind.Value = answer
+		return nil
+	}
+
+	if answer == "" {
+		return nil
+	}
+
+	i, err := strconv.Atoi(answer)
+	if err != nil || i < 1 || i > len(bind.Choices) {
+		return fmt.Errorf("invalid choice: %s", answer)
+	}
+
+	bind.Value = bind.Choices[i-1].Value
+
+	return nil
+}
+
+type recipePromptChoice struct {
+	Text  string
+	Value interface{}
+}
+
+func recipeOptionEnumValues(option models.RecipeOption) ([]interface{}, error) {
+	values, ok := option.Schema["enum"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid recipe option enum type: " + option.Label)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty recipe option enum: " + option.Label)
+	}
+	return values, nil
+}
+
+func recipeOptionEnumText(value interface{}) string {
+	switch value {
+	case nil:
+		return "<None>"
+	case true:
+		return "<True>"
+	case false:
+		return "<False>"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func applyValue(values map[string]interface{}, path string, value interface{}) error {
+	// Json pointer
+	pointer, err := gojsonpointer.NewJsonPointer(path)
+	if err != nil {
+		return err
+	}
+	_, err = pointer.Set(values, value)
+	return err
+}
@@ -117,3 +117,135 @@ func (s *RecipeFormBinderTestSuite) TestNewTypeString() {
 	item.SetText("foo")
 	s.Equal("foo", bind.Value)
 }
+
+/********************************/
+/* Recipe prompt Binder - Suite */
+/********************************/
+
+type RecipePromptBinderTestSuite struct {
+	suite.Suite
+	recipe models.RecipeInterface
+}
+
+func TestRecipePromptBinderTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(RecipePromptBinderTestSuite))
+}
+
+func (s *RecipePromptBinderTestSuite) SetupTest() {
+	s.recipe = models.NewRecipe(
+		"foo",
+		"bar",
+		"baz",
+		models.NewRepository(
+			"foo",
+			"bar",
+		),
+	)
+}
+
+/********************************/
+/* Recipe prompt Binder - Tests */
+/********************************/
+
+func (s *RecipePromptBinderTestSuite) TestNewEnum() {
+	s.recipe.AddOptions([]models.RecipeOption{
+		{
+			Label:  "Foo bar",
+			Path:   "/foo",
+			Schema: map[string]interface{}{"enum": []interface{}{true, false, nil, "foo", 123}},
+		},
+	})
+
+	bndr, err := NewRecipePromptBinder(s.recipe)
+	s.NoError(err)
+	s.Len(bndr.Binds(), 1)
+
+	bind := bndr.Binds()[0]
+	s.Equal(s.recipe.Options()[0], bind.Option)
+	s.Len(bind.Choices, 5)
+	s.Equal("<True>", bind.Choices[0].Text)
+	s.Equal("<False>", bind.Choices[1].Text)
+	s.Equal("<None>", bind.Choices[2].Text)
+	s.Equal("foo", bind.Choices[3].Text)
+	s.Equal("123", bind.Choices[4].Text)
+	s.Equal(true, bind.Value)
+
+	s.NoError(bind.SetAnswer("4"))
+	s.Equal("foo", bind.Value)
+
+	s.NoError(bind.SetAnswer(" 3 "))
+	s.Equal(nil, bind.Value)
+
+	s.NoError(bind.SetAnswer(""))
+	s.Equal(nil, bind.Value)
+
+	err = bind.SetAnswer("6")
+	s.Error(err)
+	s.Equal("invalid choice: 6", err.Error())
+
+	err = bind.SetAnswer("foo")
+	s.Error(err)
+	s.Equal("invalid choice: foo", err.Error())
+}
+
+func (s *RecipePromptBinderTestSuite) TestNewTypeString() {
+	s.recipe.AddOptions([]models.RecipeOption{
+		{
+			Label:  "Foo bar",
+			Path:   "/foo",
+			Schema: map[string]interface{}{"type": "string"},
+		},
+	})
+
+	bndr, err := NewRecipePromptBinder(s.recipe)
+	s.NoError(err)
+	s.Len(bndr.Binds(), 1)
+
+	bind := bndr.Binds()[0]
+	s.Nil(bind.Choices)
+	s.Equal("", bind.Value)
+
+	s.NoError(bind.SetAnswer("foo\n"))
+	s.Equal("foo", bind.Value)
+}
+
+func (s *RecipePromptBinderTestSuite) TestNewUnbindable() {
+	s.recipe.AddOptions([]models.RecipeOption{
+		{
+			Label:  "Foo bar",
+			Path:   "/foo",
+			Schema: map[string]interface{}{"type": "integer"},
+		},
+	})
+
+	bndr, err := NewRecipePromptBinder(s.recipe)
+	s.Error(err)
+	s.Equal("unable to bind recipe option into a prompt: Foo bar", err.Error())
+	s.Nil(bndr)
+}
+
+func (s *RecipePromptBinderTestSuite) TestApplyValues() {
+	s.recipe.AddOptions([]models.RecipeOption{
+		{
+			Label:  "Foo bar",
+			Path:   "/foo/bar",
+			Schema: map[string]interface{}{"type": "string"},
+		},
+	})
+
+	bndr, _ := NewRecipePromptBinder(s.recipe)
+	_ = bndr.Binds()[0].SetAnswer("baz")
+
+	values := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": "qux",
+		},
+	}
+	s.NoError(bndr.ApplyValues(values))
+	s.Equal(map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": "baz",
+		},
+	}, values)
+}
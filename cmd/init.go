@@ -1,18 +1,25 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"github.com/apex/log"
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gitlab.com/tslocum/cview"
+	"io"
 	"manala/binder"
 	"manala/loaders"
 	"manala/models"
 	"manala/syncer"
 	"manala/validator"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // InitCmd represents the init command
@@ -32,17 +39,36 @@ Example: manala init -> resulting in a project init in a directory (default to t
 	addRepositoryFlag(cmd, "use repository")
 	addRecipeFlag(cmd, "use recipe")
 
+	cmd.Flags().String("ui", "auto", "user interface (auto, tui, prompt)")
+
 	return cmd
 }
 
 func initRun(cmd *cobra.Command, args []string) error {
+	// User interface
+	ui, _ := cmd.Flags().GetString("ui")
+	switch ui {
+	case "auto":
+		ui = "tui"
+		if !initIsTerminal() {
+			ui = "prompt"
+		}
+	case "tui", "prompt":
+	default:
+		return fmt.Errorf("invalid user interface: %s", ui)
+	}
+
+	// Prompt reader, shared by all prompts to keep its buffer
+	reader := bufio.NewReader(cmd.InOrStdin())
+
 	// Loaders
 	repoLoader := loaders.NewRepositoryLoader(
 		viper.GetString("cache_dir"),
 		viper.GetString("repository"),
+		log.Log,
 	)
-	recLoader := loaders.NewRecipeLoader()
-	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, "", "")
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, "", "", nil, nil, log.Log)
 
 	// Directory
 	dir := "."
@@ -89,6 +115,12 @@ func initRun(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+	} else if ui == "prompt" {
+		// From recipe list prompt
+		rec, err = initRecipeListPrompt(recLoader, repo, reader, cmd.OutOrStdout())
+		if err != nil {
+			return err
+		}
 	} else {
 		// From recipe list application
 		rec, err = initRecipeListApplication(recLoader, repo)
@@ -97,18 +129,30 @@ func initRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Listed recipes could require a newer manala version
+	if err := recLoader.CheckRequires(rec); err != nil {
+		return err
+	}
+
 	// Project
 	prj := models.NewProject(dir, rec)
 
 	if rec.HasOptions() {
-		// Project form application
-		if err := initProjectFormApplication(prj); err != nil {
-			return err
+		if ui == "prompt" {
+			// Project form prompt
+			if err := initProjectFormPrompt(prj, reader, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+		} else {
+			// Project form application
+			if err := initProjectFormApplication(prj); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Sync project
-	if err := syncer.SyncProject(prj); err != nil {
+	if err := syncer.SyncProject(prj, cmd.Root().Version); err != nil {
 		return err
 	}
 
@@ -124,6 +168,29 @@ func initRecipeListApplication(recLoader loaders.RecipeLoaderInterface, repo mod
 
 	var error error
 
+	var recipes []models.RecipeInterface
+
+	// Walk into recipes
+	if err := recLoader.Walk(repo, func(rec models.RecipeInterface) {
+		recipes = append(recipes, rec)
+	}); err != nil {
+		return nil, err
+	}
+
+	// Sort recipes by name
+	sort.SliceStable(recipes, func(i, j int) bool {
+		return recipes[i].Name() < recipes[j].Name()
+	})
+
+	var recipe models.RecipeInterface
+
+	// Preview
+	preview := cview.NewTextView()
+	preview.SetBorder(true)
+	preview.SetTitle(" Preview ")
+	preview.SetBorderPadding(0, 0, 1, 1)
+	preview.SetWordWrap(true)
+
 	// List
 	list := cview.NewList()
 	list.SetBorderPadding(0, 0, 1, 0)
@@ -134,23 +201,69 @@ func initRecipeListApplication(recLoader loaders.RecipeLoaderInterface, repo mod
 			app.Stop()
 		})
 
-	var recipe models.RecipeInterface
+	var filtered []models.RecipeInterface
 
-	// Walk into recipes
-	if err := recLoader.Walk(repo, func(rec models.RecipeInterface) {
-		list.AddItem(" "+rec.Name()+" ", "   "+rec.Description(), 0, func() {
-			recipe = rec
-			app.Stop()
-		})
-	}); err != nil {
-		return nil, err
+	list.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		preview.SetText(initRecipePreview(filtered[index]))
+		preview.ScrollToBeginning()
+	})
+
+	// Fill list with recipes matching filter
+	filter := func(query string) {
+		filtered = initRecipeFilter(recipes, query)
+		list.Clear()
+		preview.Clear()
+		for _, rec := range filtered {
+			rec := rec
+			list.AddItem(" "+rec.Name()+" ", "   "+rec.Description(), 0, func() {
+				recipe = rec
+				app.Stop()
+			})
+		}
+		if len(filtered) > 0 {
+			preview.SetText(initRecipePreview(filtered[0]))
+		}
 	}
 
-	frame := cview.NewFrame(list).
+	filter("")
+
+	// Filter
+	input := cview.NewInputField()
+	input.
+		SetLabel("Filter: ").
+		SetPlaceholder("type to search by name, description or tags").
+		SetChangedFunc(filter).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape {
+				error = fmt.Errorf("operation cancelled")
+				app.Stop()
+			}
+		})
+	input.SetBorderPadding(0, 1, 1, 0)
+
+	// Navigate and select list items while typing
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyEnter:
+			list.InputHandler()(event, func(p cview.Primitive) {})
+			return nil
+		}
+		return event
+	})
+
+	flex := cview.NewFlex().
+		SetDirection(cview.FlexRow).
+		AddItem(input, 2, 0, true).
+		AddItem(cview.NewFlex().
+			AddItem(list, 0, 1, false).
+			AddItem(preview, 0, 1, false),
+			0, 1, false)
+
+	frame := cview.NewFrame(flex).
 		SetBorders(1, 1, 1, 1, 1, 1).
 		AddText("Please, select a recipe...", true, cview.AlignLeft, tcell.ColorAqua)
 
-	if err := app.SetRoot(frame, true).SetFocus(frame).Run(); err != nil {
+	if err := app.SetRoot(frame, true).SetFocus(input).Run(); err != nil {
 		return nil, err
 	}
 
@@ -165,6 +278,73 @@ func initRecipeListApplication(recLoader loaders.RecipeLoaderInterface, repo mod
 	return recipe, nil
 }
 
+// Filter recipes, keeping the ones fuzzy matching all query terms
+func initRecipeFilter(recipes []models.RecipeInterface, query string) []models.RecipeInterface {
+	filtered := []models.RecipeInterface{}
+
+	for _, rec := range recipes {
+		if initRecipeMatch(rec, query) {
+			filtered = append(filtered, rec)
+		}
+	}
+
+	return filtered
+}
+
+// Recipe fuzzy matches a query if each of its terms is found, in order but not
+// necessarily contiguous, in either its name, description or one of its tags
+func initRecipeMatch(rec models.RecipeInterface, query string) bool {
+	texts := append([]string{rec.Name(), rec.Description()}, rec.Metadata().Tags...)
+
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		found := false
+		for _, text := range texts {
+			if initFuzzyMatch(strings.ToLower(text), term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func initFuzzyMatch(text string, term string) bool {
+	runes := []rune(term)
+	i := 0
+	for _, r := range text {
+		if i < len(runes) && r == runes[i] {
+			i++
+		}
+	}
+
+	return i == len(runes)
+}
+
+// Recipe preview, made of its description, tags, options and sync units
+func initRecipePreview(rec models.RecipeInterface) string {
+	var buf bytes.Buffer
+
+	_, _ = fmt.Fprintln(&buf, rec.Description())
+	if tags := rec.Metadata().Tags; len(tags) > 0 {
+		_, _ = fmt.Fprintf(&buf, "\nTags: %s\n", strings.Join(tags, ", "))
+	}
+	if deprecated := rec.Metadata().Deprecated; deprecated != "" {
+		_, _ = fmt.Fprintf(&buf, "\nDeprecated: %s\n", deprecated)
+	}
+	if options, err := showOptions(rec); err == nil && options != "" {
+		showSection(&buf, "Options", options)
+	}
+	if syncUnits := showSyncUnits(rec); syncUnits != "" {
+		showSection(&buf, "Sync", syncUnits)
+	}
+
+	return buf.String()
+}
+
 func initProjectFormApplication(prj models.ProjectInterface) error {
 	// Application
 	app := cview.NewApplication()
@@ -246,6 +426,121 @@ func initProjectFormApplication(prj models.ProjectInterface) error {
 	return nil
 }
 
+// Full screen applications require a terminal on standard output,
+// with some basic capabilities
+func initIsTerminal() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+func initRecipeListPrompt(recLoader loaders.RecipeLoaderInterface, repo models.RepositoryInterface, reader *bufio.Reader, out io.Writer) (models.RecipeInterface, error) {
+	var recipes []models.RecipeInterface
+
+	// Walk into recipes
+	if err := recLoader.Walk(repo, func(rec models.RecipeInterface) {
+		recipes = append(recipes, rec)
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(recipes) == 0 {
+		return nil, fmt.Errorf("no recipes found")
+	}
+
+	_, _ = fmt.Fprintln(out, "Please, select a recipe...")
+	for i, rec := range recipes {
+		_, _ = fmt.Fprintf(out, "  %d) %s: %s\n", i+1, rec.Name(), rec.Description())
+	}
+
+	for {
+		answer, err := initPrompt(reader, out, fmt.Sprintf("Recipe [1-%d]: ", len(recipes)))
+		if err != nil {
+			return nil, err
+		}
+
+		// Select recipe by number...
+		if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(recipes) {
+			return recipes[i-1], nil
+		}
+
+		// ...or by name
+		for _, rec := range recipes {
+			if rec.Name() == answer {
+				return rec, nil
+			}
+		}
+
+		_, _ = fmt.Fprintf(out, "Invalid recipe: %s\n", answer)
+	}
+}
+
+func initProjectFormPrompt(prj models.ProjectInterface, reader *bufio.Reader, out io.Writer) error {
+	// Recipe prompt binder
+	bndr, err := binder.NewRecipePromptBinder(prj.Recipe())
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out, "Please, enter \""+prj.Recipe().Name()+"\" recipe options...")
+
+	for _, bind := range bndr.Binds() {
+		question := bind.Option.Label + ": "
+		if bind.Choices != nil {
+			_, _ = fmt.Fprintln(out, bind.Option.Label)
+			for i, choice := range bind.Choices {
+				_, _ = fmt.Fprintf(out, "  %d) %s\n", i+1, choice.Text)
+			}
+			question = fmt.Sprintf("Choice [1-%d] (1): ", len(bind.Choices))
+		}
+
+		for {
+			answer, err := initPrompt(reader, out, question)
+			if err != nil {
+				return err
+			}
+
+			if err := bind.SetAnswer(answer); err != nil {
+				_, _ = fmt.Fprintln(out, err.Error())
+				continue
+			}
+
+			// Validate
+			if err := validator.ValidateValue(bind.Value, bind.Option.Schema); err != nil {
+				if err, ok := err.(*validator.ValueValidationError); ok {
+					_, _ = fmt.Fprintln(out, bind.Option.Label+err.Error())
+					continue
+				}
+				return err
+			}
+
+			break
+		}
+	}
+
+	// Apply values
+	return bndr.ApplyValues(prj.Vars())
+}
+
+func initPrompt(reader *bufio.Reader, out io.Writer, question string) (string, error) {
+	_, _ = fmt.Fprint(out, question)
+
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		// Input closed before any answer
+		if err == io.EOF && answer == "" {
+			_, _ = fmt.Fprintln(out)
+			return "", fmt.Errorf("operation cancelled")
+		}
+		if err != io.EOF {
+			return "", err
+		}
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
 func init() {
 	cview.Styles = cview.Theme{
 		PrimitiveBackgroundColor:    tcell.ColorBlack,
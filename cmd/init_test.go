@@ -6,8 +6,11 @@ import (
 	"github.com/apex/log/handlers/cli"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"manala/models"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"text/template"
 )
@@ -37,6 +40,10 @@ func (s *InitTestSuite) SetupSuite() {
 }
 
 func (s *InitTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	return s.ExecuteCmdWithStdIn(dir, args, "")
+}
+
+func (s *InitTestSuite) ExecuteCmdWithStdIn(dir string, args []string, stdIn string) (*bytes.Buffer, *bytes.Buffer, error) {
 	if dir != "" {
 		_ = os.Chdir(dir)
 	}
@@ -47,6 +54,8 @@ func (s *InitTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *b
 	cmd.SilenceErrors = true
 	cmd.SilenceUsage = true
 
+	cmd.SetIn(strings.NewReader(stdIn))
+
 	stdOut := bytes.NewBufferString("")
 	cmd.SetOut(stdOut)
 	stdErr := bytes.NewBufferString("")
@@ -93,9 +102,9 @@ func (s *InitTestSuite) Test() {
 			err:  "recipe not found",
 		},
 		{
-			test:   "Use recipe and repository",
+			test: "Use recipe and repository",
 			dir:  "testdata/init/project/default",
-			args:   []string{"--recipe", "foo", "--repository", filepath.Join(s.wd, "testdata/init/repository/custom")},
+			args: []string{"--recipe", "foo", "--repository", filepath.Join(s.wd, "testdata/init/repository/custom")},
 			stdErr: `   • Synced file               path={{ .Dir }}file_custom_foo
    • Project synced           
 `,
@@ -191,3 +200,114 @@ func (s *InitTestSuite) TestProjectAlreadyExists() {
 		s.Equal("", stdErr.String())
 	})
 }
+
+func (s *InitTestSuite) TestPrompt() {
+	// Clean
+	_ = os.Remove("testdata/init/project/default/file_default_bar")
+	// Execute
+	stdOut, stdErr, err := s.ExecuteCmdWithStdIn(
+		"",
+		[]string{"testdata/init/project/default", "--ui", "prompt"},
+		"3\nbar\n2\n\nbaz\n",
+	)
+	s.NoError(err)
+	s.Equal(`Please, select a recipe...
+  1) bar: Default bar recipe
+  2) foo: Default foo recipe
+Recipe [1-2]: Invalid recipe: 3
+Recipe [1-2]: Please, enter "bar" recipe options...
+Foo
+  1) foo
+  2) bar
+Choice [1-2] (1): Bar: Bar
+- String length must be greater than or equal to 1
+Bar: `, stdOut.String())
+	s.Equal(`   • Synced file               path=testdata/init/project/default/file_default_bar
+   • Project synced           
+`, stdErr.String())
+	s.FileExists("testdata/init/project/default/file_default_bar")
+	content, _ := ioutil.ReadFile("testdata/init/project/default/file_default_bar")
+	s.Equal(`foo:
+    bar: baz
+    foo: bar
+`, string(content))
+}
+
+func (s *InitTestSuite) TestPromptCancelled() {
+	// Execute
+	stdOut, _, err := s.ExecuteCmdWithStdIn(
+		"",
+		[]string{"testdata/init/project/default", "--ui", "prompt", "--recipe", "bar"},
+		"1\n",
+	)
+	s.Error(err)
+	s.Equal("operation cancelled", err.Error())
+	s.Equal(`Please, enter "bar" recipe options...
+Foo
+  1) foo
+  2) bar
+Choice [1-2] (1): Bar: 
+`, stdOut.String())
+}
+
+func (s *InitTestSuite) TestInvalidUi() {
+	// Execute
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/init/project/default", "--ui", "invalid"},
+	)
+	s.Error(err)
+	s.Equal("invalid user interface: invalid", err.Error())
+}
+
+func (s *InitTestSuite) TestRecipeFilter() {
+	repo := models.NewRepository("foo", "bar")
+	php := models.NewRecipe("php", "Php application", "", repo)
+	php.SetMetadata(models.RecipeMetadata{Tags: []string{"docker"}})
+	node := models.NewRecipe("node", "Node application", "", repo)
+	node.SetMetadata(models.RecipeMetadata{Tags: []string{"docker", "javascript"}})
+	symfony := models.NewRecipe("symfony", "Symfony php framework", "", repo)
+	recipes := []models.RecipeInterface{node, php, symfony}
+
+	for _, t := range []struct {
+		test     string
+		query    string
+		expected []models.RecipeInterface
+	}{
+		{test: "Empty", query: "", expected: []models.RecipeInterface{node, php, symfony}},
+		{test: "Name", query: "sym", expected: []models.RecipeInterface{symfony}},
+		{test: "Fuzzy", query: "smfy", expected: []models.RecipeInterface{symfony}},
+		{test: "Case", query: "PHP", expected: []models.RecipeInterface{php, symfony}},
+		{test: "Description", query: "application", expected: []models.RecipeInterface{node, php}},
+		{test: "Tag", query: "js", expected: []models.RecipeInterface{node}},
+		{test: "Terms", query: "docker php", expected: []models.RecipeInterface{php}},
+		{test: "None", query: "python", expected: []models.RecipeInterface{}},
+	} {
+		s.Run(t.test, func() {
+			s.Equal(t.expected, initRecipeFilter(recipes, t.query))
+		})
+	}
+}
+
+func (s *InitTestSuite) TestRecipePreview() {
+	rec := models.NewRecipe("foo", "Foo recipe", "", models.NewRepository("foo", "bar"))
+	rec.SetMetadata(models.RecipeMetadata{Tags: []string{"php", "docker"}})
+	rec.AddOptions([]models.RecipeOption{
+		{Label: "Foo value", Path: "/foo", Schema: map[string]interface{}{"type": "string"}},
+	})
+	rec.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "foo.tmpl", Destination: "foo"},
+	})
+
+	s.Equal(`Foo recipe
+
+Tags: php, docker
+
+Options:
+  Foo value (/foo)
+    {"type":"string"}
+
+Sync:
+  foo.tmpl -> foo
+`, initRecipePreview(rec))
+}
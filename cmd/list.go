@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	"manala/loaders"
-	"manala/models"
+	"gopkg.in/yaml.v3"
+	"manala/manala"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 )
 
 // ListCmd represents the list command
@@ -24,29 +28,73 @@ Example: manala list -> resulting in a recipes list display`,
 
 	addRepositoryFlag(cmd, "use repository")
 
+	cmd.Flags().StringP("format", "f", "text", "output format (text, table, json, yaml)")
+	cmd.Flags().StringSliceP("tag", "t", []string{}, "filter by tags")
+
 	return cmd
 }
 
+// Describe recipe, marking it when incompatible with current manala version
+func listDescribe(recipe manala.Recipe) string {
+	if recipe.Incompatible {
+		return recipe.Description + " (incompatible, requires manala " + recipe.Requires + ")"
+	}
+	return recipe.Description
+}
+
 func listRun(cmd *cobra.Command, args []string) error {
-	// Loaders
-	repoLoader := loaders.NewRepositoryLoader(
-		viper.GetString("cache_dir"),
-		viper.GetString("repository"),
-	)
-	recLoader := loaders.NewRecipeLoader()
-
-	// Load repository
+	// Format
+	format, _ := cmd.Flags().GetString("format")
+	switch format {
+	case "text", "table", "json", "yaml":
+	default:
+		return fmt.Errorf("invalid format: %s", format)
+	}
+
 	repoName, _ := cmd.Flags().GetString("repository")
-	repo, err := repoLoader.Load(repoName)
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+
+	// List
+	recipes, err := newClient(cmd).List(manala.ListOptions{
+		Repository: repoName,
+		Tags:       tags,
+	})
 	if err != nil {
 		return err
 	}
 
-	// Walk into recipes
-	if err := recLoader.Walk(repo, func(rec models.RecipeInterface) {
-		cmd.Printf("%s: %s\n", rec.Name(), rec.Description())
-	}); err != nil {
-		return err
+	// Report
+	switch format {
+	case "table":
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tDESCRIPTION\tTAGS\tOPTIONS\tSYNC\tREPOSITORY")
+		for _, recipe := range recipes {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				recipe.Name,
+				listDescribe(recipe),
+				strings.Join(recipe.Tags, ","),
+				strconv.Itoa(recipe.Options),
+				strconv.Itoa(len(recipe.Sync)),
+				recipe.Repository,
+			)
+		}
+		return w.Flush()
+	case "json":
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(recipes)
+	case "yaml":
+		enc := yaml.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent(2)
+		if err := enc.Encode(recipes); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		for _, recipe := range recipes {
+			cmd.Printf("%s: %s\n", recipe.Name, listDescribe(recipe))
+		}
 	}
 
 	return nil
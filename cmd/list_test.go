@@ -44,6 +44,7 @@ func (s *ListTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *b
 
 	// Command
 	cmd := ListCmd()
+	cmd.Version = "1.0.0"
 	cmd.SetArgs(args)
 	cmd.SilenceErrors = true
 	cmd.SilenceUsage = true
@@ -90,6 +91,136 @@ foo: Default foo recipe
 foo: Custom foo recipe
 `,
 		},
+		{
+			test: "Table format",
+			args: []string{"--repository", "testdata/list/repository/custom", "--format", "table"},
+			stdOut: `NAME  DESCRIPTION        TAGS        OPTIONS  SYNC  REPOSITORY
+bar   Custom bar recipe  php         0        0     testdata/list/repository/custom
+foo   Custom foo recipe  php,docker  1        2     testdata/list/repository/custom
+`,
+		},
+		{
+			test: "Json format",
+			args: []string{"--repository", "testdata/list/repository/custom", "--format", "json"},
+			stdOut: `[
+  {
+    "name": "bar",
+    "description": "Custom bar recipe",
+    "repository": "testdata/list/repository/custom",
+    "options": 0,
+    "sync": [],
+    "tags": [
+      "php"
+    ]
+  },
+  {
+    "name": "foo",
+    "description": "Custom foo recipe",
+    "repository": "testdata/list/repository/custom",
+    "options": 1,
+    "sync": [
+      {
+        "source": "Makefile",
+        "destination": "Makefile"
+      },
+      {
+        "source": "docker-compose.yaml.tmpl",
+        "destination": "docker-compose.yaml",
+        "strategy": "merge"
+      }
+    ],
+    "tags": [
+      "php",
+      "docker"
+    ],
+    "deprecated": "Use bar instead",
+    "replaced_by": "bar"
+  }
+]
+`,
+		},
+		{
+			test: "Yaml format",
+			args: []string{"--repository", "testdata/list/repository/custom", "--format", "yaml"},
+			stdOut: `- name: bar
+  description: Custom bar recipe
+  repository: testdata/list/repository/custom
+  options: 0
+  sync: []
+  tags:
+    - php
+- name: foo
+  description: Custom foo recipe
+  repository: testdata/list/repository/custom
+  options: 1
+  sync:
+    - source: Makefile
+      destination: Makefile
+    - source: docker-compose.yaml.tmpl
+      destination: docker-compose.yaml
+      strategy: merge
+  tags:
+    - php
+    - docker
+  deprecated: Use bar instead
+  replaced_by: bar
+`,
+		},
+		{
+			test: "Tag",
+			args: []string{"--repository", "testdata/list/repository/custom", "--tag", "docker"},
+			stdOut: `foo: Custom foo recipe
+`,
+		},
+		{
+			test: "Tags",
+			args: []string{"--repository", "testdata/list/repository/custom", "--tag", "php", "--tag", "docker"},
+			stdOut: `foo: Custom foo recipe
+`,
+		},
+		{
+			test:   "Tag not found",
+			args:   []string{"--repository", "testdata/list/repository/custom", "--tag", "foo"},
+			stdOut: ``,
+		},
+		{
+			test: "Requires",
+			args: []string{"--repository", "testdata/list/repository/requires"},
+			stdOut: `bar: Requires bar recipe
+foo: Requires foo recipe (incompatible, requires manala >=2.0)
+`,
+		},
+		{
+			test: "Requires json format",
+			args: []string{"--repository", "testdata/list/repository/requires", "--format", "json"},
+			stdOut: `[
+  {
+    "name": "bar",
+    "description": "Requires bar recipe",
+    "repository": "testdata/list/repository/requires",
+    "options": 0,
+    "sync": [],
+    "tags": [],
+    "requires": ">=1.0"
+  },
+  {
+    "name": "foo",
+    "description": "Requires foo recipe",
+    "repository": "testdata/list/repository/requires",
+    "options": 0,
+    "sync": [],
+    "tags": [],
+    "requires": ">=2.0",
+    "incompatible": true
+  }
+]
+`,
+		},
+		{
+			test: "Invalid format",
+			args: []string{"--format", "foo"},
+			err:  "invalid format: foo",
+		},
 		{
 			test: "Use invalid repository",
 			args: []string{"--repository", "testdata/list/repository/invalid"},
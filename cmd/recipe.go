@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"manala/loaders"
+	"manala/models"
+	"path/filepath"
+)
+
+// RecipeCmd represents the recipe command
+func RecipeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recipe",
+		Short: "Recipe authoring",
+		Long: `Recipe (manala recipe) gathers commands dedicated
+to recipe authors.`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(RecipeExtractCmd())
+	cmd.AddCommand(RecipeLintCmd())
+	cmd.AddCommand(RecipeNewCmd())
+	cmd.AddCommand(RecipeTestCmd())
+
+	return cmd
+}
+
+// Load a directory repository, directory being either a repository or a single recipe.
+// In the latter case, recipe name is also returned.
+func recipeLoadDir(repoLoader loaders.RepositoryLoaderInterface, recLoader loaders.RecipeLoaderInterface, dir string) (models.RepositoryInterface, string, error) {
+	// Is directory a single recipe ?
+	recFile, err := recLoader.Find(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if recFile == nil {
+		repo, err := repoLoader.Load(dir)
+		return repo, "", err
+	}
+
+	_ = recFile.Close()
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Load recipe repository
+	repo, err := repoLoader.Load(filepath.Dir(dir))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return repo, filepath.Base(dir), nil
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"io"
+	"io/ioutil"
+	"manala/extractor"
+	"manala/loaders"
+	"os"
+	"path/filepath"
+)
+
+// RecipeExtractCmd represents the recipe extract command
+func RecipeExtractCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "extract",
+		Short: "Extract recipe",
+		Long: `Extract (manala recipe extract) will extract a recipe
+from an existing project files, turning vars literal values into
+templates actions.
+
+Example: manala recipe extract --paths Makefile,.docker --to recipes/foo -> resulting in a "foo" recipe extraction from a project directory (default to the current directory)`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE:              recipeExtractRun,
+	}
+
+	cmd.Flags().String("from", ".", "project directory")
+	cmd.Flags().StringSlice("paths", []string{}, "project paths")
+	cmd.Flags().String("to", "", "recipe directory")
+	cmd.Flags().String("vars", "", "vars mapping file (prompted if not set)")
+	cmd.Flags().String("description", "", "recipe description")
+
+	_ = cmd.MarkFlagRequired("paths")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func recipeExtractRun(cmd *cobra.Command, args []string) error {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader("", "", log.Log)
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+
+	from, _ := cmd.Flags().GetString("from")
+	if stat, err := os.Stat(from); err != nil || !stat.IsDir() {
+		return fmt.Errorf("invalid directory: %s", from)
+	}
+
+	to, _ := cmd.Flags().GetString("to")
+	to, err := filepath.Abs(to)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("recipe already exists: %s", to)
+	}
+
+	name := filepath.Base(to)
+
+	description, _ := cmd.Flags().GetString("description")
+	if description == "" {
+		description = name + " recipe"
+	}
+
+	// Vars, either from mapping file, or prompted
+	var vars map[string]interface{}
+	if file, _ := cmd.Flags().GetString("vars"); file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if vars, err = extractor.ParseVars(content); err != nil {
+			return err
+		}
+	} else {
+		if vars, err = recipeExtractVarsPrompt(bufio.NewReader(cmd.InOrStdin()), cmd.OutOrStdout()); err != nil {
+			return err
+		}
+	}
+
+	paths, _ := cmd.Flags().GetStringSlice("paths")
+
+	if err := recipeExtract(repoLoader, recLoader, from, to, description, paths, vars); err != nil {
+		// Leave no half extracted recipe behind
+		_ = os.RemoveAll(to)
+		return err
+	}
+
+	log.WithField("dir", to).Info("Recipe extracted")
+
+	return nil
+}
+
+// Extract recipe, and ensure extracted recipe loads
+func recipeExtract(repoLoader loaders.RepositoryLoaderInterface, recLoader loaders.RecipeLoaderInterface, from string, to string, description string, paths []string, vars map[string]interface{}) error {
+	if err := extractor.Extract(from, to, description, paths, vars, log.Log); err != nil {
+		return err
+	}
+
+	repo, err := repoLoader.Load(filepath.Dir(to))
+	if err != nil {
+		return err
+	}
+
+	_, err = recLoader.Load(filepath.Base(to), repo)
+
+	return err
+}
+
+func recipeExtractVarsPrompt(reader *bufio.Reader, out io.Writer) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+
+	_, _ = fmt.Fprintln(out, "Please, enter vars, along with the literal values they replace...")
+
+	for {
+		path, err := initPrompt(reader, out, "Var (dot separated, empty to end): ")
+		if err != nil {
+			return nil, err
+		}
+		if path == "" {
+			return vars, nil
+		}
+
+		value, err := initPrompt(reader, out, "Literal value: ")
+		if err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			_, _ = fmt.Fprintln(out, "empty literal value")
+			continue
+		}
+
+		if err := extractor.SetVar(vars, path, value); err != nil {
+			_, _ = fmt.Fprintln(out, err.Error())
+		}
+	}
+}
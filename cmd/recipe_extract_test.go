@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/**************************/
+/* Recipe Extract - Suite */
+/**************************/
+
+type RecipeExtractTestSuite struct {
+	suite.Suite
+	wd  string
+	dir string
+}
+
+func TestRecipeExtractTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(RecipeExtractTestSuite))
+}
+
+func (s *RecipeExtractTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+}
+
+func (s *RecipeExtractTestSuite) SetupTest() {
+	// Repository directory
+	s.dir, _ = ioutil.TempDir("", "manala-recipe-extract")
+}
+
+func (s *RecipeExtractTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *RecipeExtractTestSuite) ExecuteCmd(dir string, args []string, stdIn string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := RecipeExtractCmd()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.SetIn(strings.NewReader(stdIn))
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/**************************/
+/* Recipe Extract - Tests */
+/**************************/
+
+func (s *RecipeExtractTestSuite) TestVarsFile() {
+	_, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"--from", "testdata/recipe_extract/project", "--paths", "Makefile", "--to", filepath.Join(s.dir, "foo"), "--vars", "testdata/recipe_extract/vars.yaml"},
+		"",
+	)
+	s.NoError(err)
+	s.Contains(stdErr.String(), "Recipe extracted")
+
+	content, _ := ioutil.ReadFile(filepath.Join(s.dir, "foo", "Makefile.tmpl"))
+	s.Equal("APP = {{ .Vars.app }}\n", string(content))
+	content, _ = ioutil.ReadFile(filepath.Join(s.dir, "foo", ".manala.yaml"))
+	s.Equal(`manala:
+    description: foo recipe
+    sync:
+        - Makefile.tmpl
+
+app: my-app
+`, string(content))
+}
+
+func (s *RecipeExtractTestSuite) TestVarsPrompt() {
+	stdOut, _, err := s.ExecuteCmd(
+		"testdata/recipe_extract/project",
+		[]string{"--paths", "Makefile", "--to", filepath.Join(s.dir, "foo"), "--description", "Foo"},
+		"app.name\n\napp.name\nmy-app\n\n",
+	)
+	s.NoError(err)
+	s.Equal(`Please, enter vars, along with the literal values they replace...
+Var (dot separated, empty to end): Literal value: empty literal value
+Var (dot separated, empty to end): Literal value: Var (dot separated, empty to end): `, stdOut.String())
+
+	content, _ := ioutil.ReadFile(filepath.Join(s.dir, "foo", "Makefile.tmpl"))
+	s.Equal("APP = {{ .Vars.app.name }}\n", string(content))
+}
+
+func (s *RecipeExtractTestSuite) TestVarsPromptCancelled() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"--from", "testdata/recipe_extract/project", "--paths", "Makefile", "--to", filepath.Join(s.dir, "foo")},
+		"app",
+	)
+	s.Error(err)
+	s.Equal("operation cancelled", err.Error())
+}
+
+func (s *RecipeExtractTestSuite) TestAlreadyExists() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"--from", "testdata/recipe_extract/project", "--paths", "Makefile", "--to", s.dir},
+		"",
+	)
+	s.Error(err)
+	s.Equal("recipe already exists: "+s.dir, err.Error())
+}
+
+func (s *RecipeExtractTestSuite) TestInvalidDirectory() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"--from", "testdata/recipe_extract/not_found", "--paths", "Makefile", "--to", filepath.Join(s.dir, "foo")},
+		"",
+	)
+	s.Error(err)
+	s.Equal("invalid directory: testdata/recipe_extract/not_found", err.Error())
+}
+
+func (s *RecipeExtractTestSuite) TestFailure() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"--from", "testdata/recipe_extract/project", "--paths", "Makefile,not_found", "--to", filepath.Join(s.dir, "foo"), "--vars", "testdata/recipe_extract/vars.yaml"},
+		"",
+	)
+	s.Error(err)
+	s.Equal("path not found: not_found", err.Error())
+
+	// No half extracted recipe left behind
+	s.NoDirExists(filepath.Join(s.dir, "foo"))
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"manala/linter"
+	"manala/loaders"
+)
+
+// RecipeLintCmd represents the recipe lint command
+func RecipeLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [dir]",
+		Short: "Lint recipes",
+		Long: `Lint (manala recipe lint) will report all problems of
+either a repository recipes, or a single recipe.
+
+Example: manala recipe lint -> resulting in a lint report of a directory (default to the current directory)`,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE:              recipeLintRun,
+	}
+
+	return cmd
+}
+
+func recipeLintRun(cmd *cobra.Command, args []string) error {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader(
+		viper.GetString("cache_dir"),
+		"",
+		log.Log,
+	)
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+
+	// Directory
+	dir := "."
+	if len(args) != 0 {
+		// Get directory from first command arg
+		dir = args[0]
+	}
+
+	repo, recName, err := recipeLoadDir(repoLoader, recLoader, dir)
+	if err != nil {
+		return err
+	}
+
+	// Keep synced files quiet, unless debugging
+	var logger log.Interface = &log.Logger{Handler: discard.Default}
+	if viper.GetBool("debug") {
+		logger = log.Log
+	}
+
+	var problems []linter.Problem
+
+	if recName != "" {
+		problems = linter.LintRecipe(recLoader, recName, repo, cmd.Root().Version, logger)
+	} else {
+		problems, err = linter.LintRepository(recLoader, repo, cmd.Root().Version, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Report
+	for _, problem := range problems {
+		cmd.Printf("%s: %s\n", problem.Recipe, problem.Message)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("recipe lint failed (%d problems)", len(problems))
+	}
+
+	log.Info("Recipes linted")
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"testing"
+)
+
+/***********************/
+/* Recipe Lint - Suite */
+/***********************/
+
+type RecipeLintTestSuite struct {
+	suite.Suite
+	wd string
+}
+
+func TestRecipeLintTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(RecipeLintTestSuite))
+}
+
+func (s *RecipeLintTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+}
+
+func (s *RecipeLintTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := RecipeLintCmd()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/***********************/
+/* Recipe Lint - Tests */
+/***********************/
+
+func (s *RecipeLintTestSuite) TestRepository() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_lint/repository"},
+	)
+	s.Error(err)
+	s.Equal("recipe lint failed (2 problems)", err.Error())
+	s.Equal(`invalid: sync source "not_found" does not exist
+invalid: default value at "/foo" is not part of its enum
+`, stdOut.String())
+	s.Equal("", stdErr.String())
+}
+
+func (s *RecipeLintTestSuite) TestRepositoryNotFound() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_lint/not_found"},
+	)
+	s.Error(err)
+	s.Equal("\"testdata/recipe_lint/not_found\" directory does not exists", err.Error())
+}
+
+func (s *RecipeLintTestSuite) TestRecipe() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_lint/repository/valid"},
+	)
+	s.NoError(err)
+	s.Equal("", stdOut.String())
+	s.Equal("   • Recipes linted           \n", stdErr.String())
+}
+
+func (s *RecipeLintTestSuite) TestRecipeCurrentDirectory() {
+	stdOut, _, err := s.ExecuteCmd(
+		"testdata/recipe_lint/repository/invalid",
+		[]string{},
+	)
+	s.Error(err)
+	s.Equal("recipe lint failed (2 problems)", err.Error())
+	s.Equal(`invalid: sync source "not_found" does not exist
+invalid: default value at "/foo" is not part of its enum
+`, stdOut.String())
+}
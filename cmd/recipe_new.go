@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RecipeNewCmd represents the recipe new command
+func RecipeNewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new name",
+		Short: "Create recipe",
+		Long: `New (manala recipe new) will create a skeleton recipe,
+including an option, a helper, a template, and a test case.
+
+Example: manala recipe new foo -> resulting in a "foo" recipe creation in a repository directory (default to the current directory)`,
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE:              recipeNewRun,
+	}
+
+	addRepositoryFlag(cmd, "repository directory")
+
+	cmd.Flags().String("description", "", "recipe description")
+
+	return cmd
+}
+
+// Skeleton recipe files, with recipe name and quoted description placeholders
+var recipeNewFiles = []struct {
+	path    string
+	content string
+}{
+	{
+		path: ".manala.yaml",
+		content: `manala:
+    description: %description%
+    sync:
+        - foo.yaml.tmpl
+
+# Foo value
+# @option {"label": "Foo value"}
+# @schema {"enum": ["bar", "baz"]}
+foo: bar
+`,
+	},
+	{
+		path: "_helpers.tmpl",
+		content: `{{- define "foo" -}}
+foo: {{ .Vars.foo }}
+{{- end -}}
+`,
+	},
+	{
+		path: "foo.yaml.tmpl",
+		content: `{{ include "foo" . }}
+`,
+	},
+	{
+		path: "tests/default/.manala.yaml",
+		content: `manala:
+    recipe: %name%
+`,
+	},
+	{
+		path: "tests/default/expected/foo.yaml",
+		content: `foo: bar
+`,
+	},
+}
+
+func recipeNewRun(cmd *cobra.Command, args []string) error {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader("", ".", log.Log)
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+
+	// Name
+	name := args[0]
+	if !loaders.IsRecipeName(name) {
+		return fmt.Errorf("invalid recipe name: %s", name)
+	}
+
+	// Load repository, only from a local directory, as recipe is created into it
+	repoName, _ := cmd.Flags().GetString("repository")
+	if repoName != "" {
+		if stat, err := os.Stat(repoName); err != nil || !stat.IsDir() {
+			return fmt.Errorf("invalid repository directory: %s", repoName)
+		}
+	}
+	repo, err := repoLoader.Load(repoName)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(repo.Dir(), name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("recipe already exists: %s", name)
+	}
+
+	description, _ := cmd.Flags().GetString("description")
+	if description == "" {
+		description = name + " recipe"
+	}
+
+	replacer := strings.NewReplacer(
+		"%name%", name,
+		"%description%", strconv.Quote(description),
+	)
+
+	if err := recipeNewCreate(recLoader, repo, name, dir, replacer); err != nil {
+		// Leave no half created recipe behind
+		_ = os.RemoveAll(dir)
+		return err
+	}
+
+	log.WithField("dir", dir).Info("Recipe created")
+
+	return nil
+}
+
+// Create recipe files, and ensure created recipe loads
+func recipeNewCreate(recLoader loaders.RecipeLoaderInterface, repo models.RepositoryInterface, name string, dir string, replacer *strings.Replacer) error {
+	for _, file := range recipeNewFiles {
+		path := filepath.Join(dir, filepath.FromSlash(file.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, []byte(replacer.Replace(file.content)), 0666); err != nil {
+			return err
+		}
+	}
+
+	_, err := recLoader.Load(name, repo)
+
+	return err
+}
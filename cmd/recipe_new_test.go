@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/tester"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/**********************/
+/* Recipe New - Suite */
+/**********************/
+
+type RecipeNewTestSuite struct {
+	suite.Suite
+	wd  string
+	dir string
+}
+
+func TestRecipeNewTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(RecipeNewTestSuite))
+}
+
+func (s *RecipeNewTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+}
+
+func (s *RecipeNewTestSuite) SetupTest() {
+	// Repository directory
+	s.dir, _ = ioutil.TempDir("", "manala-recipe-new")
+}
+
+func (s *RecipeNewTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *RecipeNewTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := RecipeNewCmd()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/**********************/
+/* Recipe New - Tests */
+/**********************/
+
+func (s *RecipeNewTestSuite) TestRecipe() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"foo", "--repository", s.dir},
+	)
+	s.NoError(err)
+	s.Equal("", stdOut.String())
+	s.Contains(stdErr.String(), "Recipe created")
+
+	for _, file := range []string{
+		".manala.yaml",
+		"_helpers.tmpl",
+		"foo.yaml.tmpl",
+		"tests/default/.manala.yaml",
+		"tests/default/expected/foo.yaml",
+	} {
+		s.FileExists(filepath.Join(s.dir, "foo", file))
+	}
+
+	// Recipe
+	repoLoader := loaders.NewRepositoryLoader("", "", log.Log)
+	repo, _ := repoLoader.Load(s.dir)
+	recLoader := loaders.NewRecipeLoader("", log.Log)
+	rec, err := recLoader.Load("foo", repo)
+	s.NoError(err)
+	s.Equal("foo recipe", rec.Description())
+	s.Len(rec.Options(), 1)
+
+	// Test case
+	results, err := tester.RunRecipe(repoLoader, recLoader, "foo", repo, "", false, &log.Logger{Handler: discard.Default})
+	s.NoError(err)
+	s.Len(results, 1)
+	s.True(results[0].Passed())
+}
+
+func (s *RecipeNewTestSuite) TestRecipeDescription() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"foo", "--repository", s.dir, "--description", "Foo: \"bar\""},
+	)
+	s.NoError(err)
+
+	repo, _ := loaders.NewRepositoryLoader("", "", log.Log).Load(s.dir)
+	rec, err := loaders.NewRecipeLoader("", log.Log).Load("foo", repo)
+	s.NoError(err)
+	s.Equal("Foo: \"bar\"", rec.Description())
+}
+
+func (s *RecipeNewTestSuite) TestRecipeCurrentDirectory() {
+	_, _, err := s.ExecuteCmd(
+		s.dir,
+		[]string{"foo"},
+	)
+	s.NoError(err)
+	s.FileExists(filepath.Join(s.dir, "foo", ".manala.yaml"))
+}
+
+func (s *RecipeNewTestSuite) TestRecipeAlreadyExists() {
+	_ = os.Mkdir(filepath.Join(s.dir, "foo"), 0755)
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"foo", "--repository", s.dir},
+	)
+	s.Error(err)
+	s.Equal("recipe already exists: foo", err.Error())
+}
+
+func (s *RecipeNewTestSuite) TestRecipeInvalidName() {
+	for _, name := range []string{"_helpers", ".foo", "foo/bar"} {
+		_, _, err := s.ExecuteCmd(
+			"",
+			[]string{name, "--repository", s.dir},
+		)
+		s.Error(err)
+		s.Equal("invalid recipe name: "+name, err.Error())
+	}
+}
+
+func (s *RecipeNewTestSuite) TestRecipeInvalidRepository() {
+	for _, repository := range []string{"https://github.com/foo/bar.git", filepath.Join(s.dir, "not_found")} {
+		_, _, err := s.ExecuteCmd(
+			"",
+			[]string{"foo", "--repository", repository},
+		)
+		s.Error(err)
+		s.Equal("invalid repository directory: "+repository, err.Error())
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"manala/loaders"
+	"manala/tester"
+)
+
+// RecipeTestCmd represents the recipe test command
+func RecipeTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [dir]",
+		Short: "Test recipes",
+		Long: `Test (manala recipe test) will sync recipes test cases projects,
+and compare them to their expected ones, either for a repository
+recipes, or a single recipe.
+
+Example: manala recipe test -> resulting in a test report of a directory (default to the current directory)`,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE:              recipeTestRun,
+	}
+
+	cmd.Flags().Bool("update-golden", false, "update expected projects")
+
+	return cmd
+}
+
+func recipeTestRun(cmd *cobra.Command, args []string) error {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader(
+		viper.GetString("cache_dir"),
+		"",
+		log.Log,
+	)
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+
+	// Directory
+	dir := "."
+	if len(args) != 0 {
+		// Get directory from first command arg
+		dir = args[0]
+	}
+
+	repo, recName, err := recipeLoadDir(repoLoader, recLoader, dir)
+	if err != nil {
+		return err
+	}
+
+	update, _ := cmd.Flags().GetBool("update-golden")
+
+	// Keep loaded projects and synced files quiet, unless debugging
+	var logger log.Interface = &log.Logger{Handler: discard.Default}
+	if viper.GetBool("debug") {
+		logger = log.Log
+	}
+
+	var results []tester.Result
+
+	if recName != "" {
+		results, err = tester.RunRecipe(repoLoader, recLoader, recName, repo, cmd.Root().Version, update, logger)
+	} else {
+		results, err = tester.RunRepository(repoLoader, recLoader, repo, cmd.Root().Version, update, logger)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Report
+	failures := 0
+	for _, result := range results {
+		switch {
+		case result.Updated:
+			cmd.Printf("UPDATED %s/%s\n", result.Recipe, result.Case)
+		case result.Passed():
+			cmd.Printf("PASS    %s/%s\n", result.Recipe, result.Case)
+		default:
+			failures++
+			cmd.Printf("FAIL    %s/%s\n", result.Recipe, result.Case)
+			if result.Err != nil {
+				cmd.Printf("%s\n", result.Err)
+			}
+			for _, diff := range result.Diffs {
+				cmd.Print(diff)
+			}
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("recipe test failed (%d failures)", failures)
+	}
+
+	log.Info("Recipes tested")
+
+	return nil
+}
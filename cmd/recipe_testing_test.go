@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"testing"
+)
+
+/***********************/
+/* Recipe Test - Suite */
+/***********************/
+
+type RecipeTestTestSuite struct {
+	suite.Suite
+	wd string
+}
+
+func TestRecipeTestTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(RecipeTestTestSuite))
+}
+
+func (s *RecipeTestTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+}
+
+func (s *RecipeTestTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := RecipeTestCmd()
+	cmd.Version = "1.0.0"
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/***********************/
+/* Recipe Test - Tests */
+/***********************/
+
+func (s *RecipeTestTestSuite) TestRepository() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_testing/repository"},
+	)
+	s.Error(err)
+	s.Equal("recipe test failed (1 failures)", err.Error())
+	s.Equal(`PASS    bar/default
+FAIL    foo/default
+--- expected/file
++++ actual/file
+@@ -1 +1 @@
+-foo: baz
++foo: bar
+`, stdOut.String())
+	s.Equal("", stdErr.String())
+}
+
+func (s *RecipeTestTestSuite) TestRepositoryNotFound() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_testing/not_found"},
+	)
+	s.Error(err)
+	s.Equal("\"testdata/recipe_testing/not_found\" directory does not exists", err.Error())
+}
+
+func (s *RecipeTestTestSuite) TestRecipe() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_testing/repository/bar"},
+	)
+	s.NoError(err)
+	s.Equal("PASS    bar/default\n", stdOut.String())
+	s.Equal("   • Recipes tested           \n", stdErr.String())
+}
+
+func (s *RecipeTestTestSuite) TestRecipeRequires() {
+	stdOut, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/recipe_testing/requires/foo"},
+	)
+	s.Error(err)
+	s.Equal("recipe test failed (1 failures)", err.Error())
+	s.Equal(`FAIL    foo/default
+recipe "foo" requires manala >=2.0, current version is 1.0.0, please upgrade manala
+`, stdOut.String())
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"github.com/apex/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"manala/manala"
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -36,3 +38,18 @@ func addRepositoryFlag(cmd *cobra.Command, usage string) {
 func addRecipeFlag(cmd *cobra.Command, usage string) {
 	cmd.Flags().StringP("recipe", "i", "", usage)
 }
+
+func addValuesFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("values", []string{}, "merge values files, in order")
+	cmd.Flags().StringArray("set", []string{}, "set a value (key.path=value)")
+}
+
+// Get a client, configured from command and viper globals
+func newClient(cmd *cobra.Command) *manala.Client {
+	return manala.NewClient(manala.Options{
+		CacheDir:   viper.GetString("cache_dir"),
+		Repository: viper.GetString("repository"),
+		Version:    cmd.Root().Version,
+		Logger:     log.Log,
+	})
+}
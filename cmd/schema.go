@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"manala/validator"
+)
+
+// SchemaCmd represents the schema command
+func SchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [recipe]",
+		Short: "Export recipe schema",
+		Long: `Schema (manala schema) will export recipe json schema,
+suitable for manala.yaml completion and validation in editors.
+
+Example: manala schema -> resulting in the current project recipe schema display`,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE:              schemaRun,
+	}
+
+	addRepositoryFlag(cmd, "use repository")
+
+	cmd.Flags().String("output", "", "output file")
+
+	return cmd
+}
+
+func schemaRun(cmd *cobra.Command, args []string) error {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader(
+		viper.GetString("cache_dir"),
+		viper.GetString("repository"),
+		log.Log,
+	)
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+	repoName, _ := cmd.Flags().GetString("repository")
+
+	var rec models.RecipeInterface
+
+	if len(args) != 0 {
+		// Load repository
+		repo, err := repoLoader.Load(repoName)
+		if err != nil {
+			return err
+		}
+
+		// Load recipe from first command arg
+		rec, err = recLoader.Load(args[0], repo)
+		if err != nil {
+			return err
+		}
+	} else {
+		prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, repoName, "", nil, nil, log.Log)
+
+		// Find project file
+		prjFile, err := prjLoader.Find(".", true)
+		if err != nil {
+			return err
+		}
+
+		if prjFile == nil {
+			return fmt.Errorf("project not found: .")
+		}
+
+		// Load project recipe
+		prj, err := prjLoader.Load(prjFile)
+		if err != nil {
+			return err
+		}
+		rec = prj.Recipe()
+	}
+
+	content, err := json.MarshalIndent(validator.ProjectSchema(rec), "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+
+	// Output
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		cmd.Print(string(content))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(output, content, 0666); err != nil {
+		return err
+	}
+
+	log.WithField("path", output).Info("Schema exported")
+
+	return nil
+}
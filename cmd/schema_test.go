@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/******************/
+/* Schema - Suite */
+/******************/
+
+type SchemaTestSuite struct {
+	suite.Suite
+	wd string
+}
+
+func TestSchemaTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(SchemaTestSuite))
+}
+
+func (s *SchemaTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+	// Default repository
+	viper.SetDefault(
+		"repository",
+		filepath.Join(s.wd, "testdata/schema/repository/default"),
+	)
+}
+
+func (s *SchemaTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := SchemaCmd()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/******************/
+/* Schema - Tests */
+/******************/
+
+var schemaFoo = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "additionalProperties": false,
+  "description": "Default foo recipe",
+  "properties": {
+    "foo": {
+      "description": "Foo value",
+      "enum": [
+        "bar",
+        "baz"
+      ]
+    },
+    "manala": {
+      "additionalProperties": false,
+      "description": "Manala config",
+      "properties": {
+        "recipe": {
+          "default": "foo",
+          "description": "Recipe name",
+          "type": "string"
+        },
+        "repository": {
+          "description": "Recipe repository source",
+          "type": "string"
+        }
+      },
+      "required": [
+        "recipe"
+      ],
+      "type": "object"
+    }
+  },
+  "title": "foo",
+  "type": "object"
+}
+`
+
+func (s *SchemaTestSuite) TestRecipe() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"foo"},
+	)
+	s.NoError(err)
+	s.Equal(schemaFoo, stdOut.String())
+	s.Equal("", stdErr.String())
+}
+
+func (s *SchemaTestSuite) TestRecipeNotFound() {
+	_, _, err := s.ExecuteCmd(
+		"",
+		[]string{"bar"},
+	)
+	s.Error(err)
+	s.Equal("recipe not found", err.Error())
+}
+
+func (s *SchemaTestSuite) TestProject() {
+	stdOut, _, err := s.ExecuteCmd(
+		"testdata/schema/project/default",
+		[]string{},
+	)
+	s.NoError(err)
+	s.Equal(schemaFoo, stdOut.String())
+}
+
+func (s *SchemaTestSuite) TestProjectNotFound() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"testdata/schema/project/not_found",
+		[]string{},
+	)
+	s.Error(err)
+	s.Equal("project not found: .", err.Error())
+	s.Equal("", stdOut.String())
+	s.Equal("", stdErr.String())
+}
+
+func (s *SchemaTestSuite) TestOutput() {
+	_ = os.Remove("testdata/schema/schema.json")
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"foo", "--output", "testdata/schema/schema.json"},
+	)
+	s.NoError(err)
+	s.Equal("", stdOut.String())
+	s.Equal("   • Schema exported           path=testdata/schema/schema.json\n", stdErr.String())
+	content, _ := ioutil.ReadFile("testdata/schema/schema.json")
+	s.Equal(schemaFoo, string(content))
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ShowCmd represents the show command
+func ShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show recipe",
+		Short: "Show recipe",
+		Long: `Show (manala show) will show recipe details, such as
+default vars, options, sync units, and readme.
+
+Example: manala show foo -> resulting in a "foo" recipe details display`,
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE:              showRun,
+	}
+
+	addRepositoryFlag(cmd, "use repository")
+
+	return cmd
+}
+
+// Recipe readme file
+var showReadmeFile = "README.md"
+
+func showRun(cmd *cobra.Command, args []string) error {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader(
+		viper.GetString("cache_dir"),
+		viper.GetString("repository"),
+		log.Log,
+	)
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
+
+	// Load repository
+	repoName, _ := cmd.Flags().GetString("repository")
+	repo, err := repoLoader.Load(repoName)
+	if err != nil {
+		return err
+	}
+
+	// Load recipe from first command arg
+	rec, err := recLoader.Load(args[0], repo)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+
+	_, _ = fmt.Fprintf(out, "Name:        %s\n", rec.Name())
+	_, _ = fmt.Fprintf(out, "Description: %s\n", rec.Description())
+	_, _ = fmt.Fprintf(out, "Repository:  %s\n", rec.Repository().Src())
+
+	// Metadata
+	metadata := rec.Metadata()
+	if len(metadata.Tags) > 0 {
+		_, _ = fmt.Fprintf(out, "Tags:        %s\n", strings.Join(metadata.Tags, ", "))
+	}
+	if len(metadata.Maintainers) > 0 {
+		_, _ = fmt.Fprintf(out, "Maintainers: %s\n", strings.Join(metadata.Maintainers, ", "))
+	}
+	if metadata.Homepage != "" {
+		_, _ = fmt.Fprintf(out, "Homepage:    %s\n", metadata.Homepage)
+	}
+	if metadata.Deprecated != "" {
+		_, _ = fmt.Fprintf(out, "Deprecated:  %s\n", metadata.Deprecated)
+	}
+	if metadata.ReplacedBy != "" {
+		_, _ = fmt.Fprintf(out, "Replaced by: %s\n", metadata.ReplacedBy)
+	}
+	if rec.Requires() != "" {
+		// Incompatible recipes could still be shown
+		if recLoader.CheckRequires(rec) != nil {
+			_, _ = fmt.Fprintf(out, "Requires:    manala %s (incompatible, current version is %s)\n", rec.Requires(), cmd.Root().Version)
+		} else {
+			_, _ = fmt.Fprintf(out, "Requires:    manala %s\n", rec.Requires())
+		}
+	}
+
+	// Vars
+	if len(rec.Vars()) > 0 {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(4)
+		if err := enc.Encode(rec.Vars()); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		showSection(out, "Vars", buf.String())
+	}
+
+	// Options
+	options, err := showOptions(rec)
+	if err != nil {
+		return err
+	}
+	if options != "" {
+		showSection(out, "Options", options)
+	}
+
+	// Sync units
+	if syncUnits := showSyncUnits(rec); syncUnits != "" {
+		showSection(out, "Sync", syncUnits)
+	}
+
+	// Readme
+	readme, err := ioutil.ReadFile(filepath.Join(rec.Dir(), showReadmeFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(readme) > 0 {
+		showSection(out, "Readme", string(readme))
+	}
+
+	return nil
+}
+
+// Get recipe options, with their path and schema
+func showOptions(rec models.RecipeInterface) (string, error) {
+	var buf bytes.Buffer
+	for _, option := range rec.Options() {
+		schema, err := json.Marshal(option.Schema)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(fmt.Sprintf("%s (%s)\n  %s\n", option.Label, option.Path, schema))
+	}
+
+	return buf.String(), nil
+}
+
+// Get recipe sync units, with their destination, foreach and strategy
+func showSyncUnits(rec models.RecipeInterface) string {
+	var buf bytes.Buffer
+	for _, unit := range rec.SyncUnits() {
+		buf.WriteString(unit.Source)
+		if unit.Destination != unit.Source {
+			buf.WriteString(" -> " + unit.Destination)
+		}
+		if unit.Foreach != "" {
+			buf.WriteString(" (foreach " + unit.Foreach + ")")
+		}
+		if unit.Strategy != "" {
+			buf.WriteString(" (" + unit.Strategy + ")")
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// Print a titled section, with indented content
+func showSection(out io.Writer, title string, content string) {
+	_, _ = fmt.Fprintf(out, "\n%s:\n", title)
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		if line == "" {
+			_, _ = fmt.Fprintln(out)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "  %s\n", line)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/****************/
+/* Show - Suite */
+/****************/
+
+type ShowTestSuite struct {
+	suite.Suite
+	wd string
+}
+
+func TestShowTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(ShowTestSuite))
+}
+
+func (s *ShowTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+	// Default repository
+	viper.SetDefault(
+		"repository",
+		filepath.Join(s.wd, "testdata/show/repository/default"),
+	)
+}
+
+func (s *ShowTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := ShowCmd()
+	cmd.Version = "1.0.0"
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/****************/
+/* Show - Tests */
+/****************/
+
+func (s *ShowTestSuite) Test() {
+	for _, t := range []struct {
+		test   string
+		args   []string
+		err    string
+		stdErr string
+		stdOut string
+	}{
+		{
+			test: "Recipe",
+			args: []string{"foo", "--repository", "testdata/show/repository/default"},
+			stdOut: `Name:        foo
+Description: Default foo recipe
+Repository:  testdata/show/repository/default
+Tags:        php, docker
+Maintainers: Foo <foo@example.com>
+Homepage:    https://example.com/foo
+Deprecated:  Use bar instead
+Replaced by: bar
+
+Vars:
+  bar:
+      baz: qux
+  foo: bar
+
+Options:
+  Foo value (/foo)
+    {"enum":["bar","baz"]}
+
+Sync:
+  Makefile
+  docker-compose.yaml.tmpl -> docker-compose.yaml (merge)
+
+Readme:
+  # Foo
+
+  Foo recipe readme.
+`,
+		},
+		{
+			test: "Recipe without details",
+			args: []string{"bar", "--repository", "testdata/show/repository/default"},
+			stdOut: `Name:        bar
+Description: Default bar recipe
+Repository:  testdata/show/repository/default
+`,
+		},
+		{
+			test: "Recipe incompatible",
+			args: []string{"requires", "--repository", "testdata/show/repository/default"},
+			stdOut: `Name:        requires
+Description: Default requires recipe
+Repository:  testdata/show/repository/default
+Requires:    manala >=2.0 (incompatible, current version is 1.0.0)
+`,
+		},
+		{
+			test: "Recipe not found",
+			args: []string{"baz"},
+			err:  "recipe not found",
+		},
+	} {
+		s.Run(t.test, func() {
+			// Execute
+			stdOut, stdErr, err := s.ExecuteCmd(
+				"",
+				t.args,
+			)
+			// Tests
+			if t.err != "" {
+				s.Error(err)
+				s.Equal(t.err, err.Error())
+			} else {
+				s.NoError(err)
+			}
+			s.Equal(t.stdOut, stdOut.String())
+			s.Equal(t.stdErr, stdErr.String())
+		})
+	}
+}
@@ -1,16 +1,8 @@
 package cmd
 
 import (
-	"fmt"
-	"github.com/apex/log"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	"manala/loaders"
-	"manala/syncer"
-	"manala/validator"
-	"os"
-	"path/filepath"
-	"strings"
+	"manala/manala"
 )
 
 // UpdateCmd represents the update command
@@ -30,6 +22,7 @@ Example: manala update -> resulting in an update in a directory (default to the
 
 	addRepositoryFlag(cmd, "force repository")
 	addRecipeFlag(cmd, "force recipe")
+	addValuesFlags(cmd)
 
 	cmd.Flags().BoolP("recursive", "r", false, "recursive")
 
@@ -37,99 +30,28 @@ Example: manala update -> resulting in an update in a directory (default to the
 }
 
 func updateRun(cmd *cobra.Command, args []string) error {
-	// Loaders
-	repoLoader := loaders.NewRepositoryLoader(
-		viper.GetString("cache_dir"),
-		viper.GetString("repository"),
-	)
-	recLoader := loaders.NewRecipeLoader()
-	repoName, _ := cmd.Flags().GetString("repository")
-	recName, _ := cmd.Flags().GetString("recipe")
-	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, repoName, recName)
-
 	// Directory
 	dir := "."
 	if len(args) != 0 {
 		// Get directory from first command arg
 		dir = args[0]
-		if _, err := os.Stat(dir); err != nil {
-			return fmt.Errorf("invalid directory: %s", dir)
-		}
 	}
 
+	repoName, _ := cmd.Flags().GetString("repository")
+	recName, _ := cmd.Flags().GetString("recipe")
+	valuesFiles, _ := cmd.Flags().GetStringSlice("values")
+	setValues, _ := cmd.Flags().GetStringArray("set")
 	recursive, _ := cmd.Flags().GetBool("recursive")
 
-	if recursive == true {
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			// Only directories
-			if err != nil || !info.IsDir() {
-				return err
-			}
-
-			// Only not dotted directories
-			// (except - of course - current directory)
-			if strings.HasPrefix(filepath.Base(path), ".") && (path != ".") {
-				return filepath.SkipDir
-			}
-
-			// Find project file
-			prjFile, err := prjLoader.Find(path, false)
-			if err != nil {
-				return err
-			}
-
-			// Update
-			if prjFile != nil {
-				if err := updateRunFunc(prjLoader, prjFile); err != nil {
-					return err
-				}
-			}
-
-			return nil
-		})
-		if err != nil && !os.IsNotExist(err) {
-			return err
-		}
-	} else {
-		// Find project file
-		prjFile, err := prjLoader.Find(dir, true)
-		if err != nil {
-			return err
-		}
-
-		if prjFile == nil {
-			return fmt.Errorf("project not found: %s", dir)
-		}
-
-		// Update
-		if err = updateRunFunc(prjLoader, prjFile); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func updateRunFunc(prjLoader loaders.ProjectLoaderInterface, prjFile *os.File) error {
-	// Load project
-	prj, err := prjLoader.Load(prjFile)
-	if err != nil {
-		return err
-	}
-
-	// Validate project
-	if err := validator.ValidateProject(prj); err != nil {
-		return err
-	}
-
-	log.Info("Project validated")
-
-	// Sync project
-	if err := syncer.SyncProject(prj); err != nil {
-		return err
-	}
-
-	log.Info("Project synced")
-
-	return nil
+	// Update
+	_, err := newClient(cmd).Update(manala.UpdateOptions{
+		Dir:         dir,
+		Repository:  repoName,
+		Recipe:      recName,
+		Recursive:   recursive,
+		ValuesFiles: valuesFiles,
+		SetValues:   setValues,
+	})
+
+	return err
 }
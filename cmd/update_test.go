@@ -124,6 +124,20 @@ func (s *UpdateTestSuite) Test() {
 `,
 			file: "testdata/update/project/default/file_default_bar",
 		},
+		{
+			test: "Deprecated recipe",
+			dir:  "testdata/update/project/deprecated",
+			args: []string{},
+			stdErr: `   • Project loaded            recipe=deprecated repository=
+   • Repository loaded        
+   • Recipe loaded            
+   • Recipe deprecated: Use foo instead recipe=deprecated replaced_by=foo
+   • Project validated        
+   • Synced file               path={{ .Dir }}file_default_deprecated
+   • Project synced           
+`,
+			file: "testdata/update/project/deprecated/file_default_deprecated",
+		},
 		{
 			test: "Default force invalid recipe",
 			dir:  "testdata/update/project/default",
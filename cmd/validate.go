@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"manala/manala"
+	"path/filepath"
+)
+
+// ValidateCmd represents the validate command
+func ValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [dir]",
+		Short: "Validate project",
+		Long: `Validate (manala validate) will validate project variables
+defined in manala.yaml, against recipe schema.
+
+Example: manala validate -> resulting in a validation report of a directory (default to the current directory)`,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE:              validateRun,
+	}
+
+	addRepositoryFlag(cmd, "force repository")
+	addRecipeFlag(cmd, "force recipe")
+	addValuesFlags(cmd)
+
+	cmd.Flags().BoolP("recursive", "r", false, "recursive")
+	cmd.Flags().StringP("format", "f", "text", "output format (text, json, sarif)")
+
+	return cmd
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	// Format
+	format, _ := cmd.Flags().GetString("format")
+	switch format {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("invalid format: %s", format)
+	}
+
+	// Directory
+	dir := "."
+	if len(args) != 0 {
+		// Get directory from first command arg
+		dir = args[0]
+	}
+
+	repoName, _ := cmd.Flags().GetString("repository")
+	recName, _ := cmd.Flags().GetString("recipe")
+	valuesFiles, _ := cmd.Flags().GetStringSlice("values")
+	setValues, _ := cmd.Flags().GetStringArray("set")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+
+	// Validate
+	violations, err := newClient(cmd).Validate(manala.ValidateOptions{
+		Dir:         dir,
+		Repository:  repoName,
+		Recipe:      recName,
+		Recursive:   recursive,
+		ValuesFiles: valuesFiles,
+		SetValues:   setValues,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Report
+	switch format {
+	case "json":
+		if err := validateReportJson(cmd.OutOrStdout(), violations); err != nil {
+			return err
+		}
+	case "sarif":
+		if err := validateReportSarif(cmd.OutOrStdout(), violations, cmd.Root().Version); err != nil {
+			return err
+		}
+	default:
+		for _, violation := range violations {
+			pointer := violation.Pointer
+			if pointer == "" {
+				pointer = "(root)"
+			}
+			// Set values violations are not located in any file
+			location := violation.File
+			if location == "" {
+				location = "--set"
+			} else if violation.Line > 0 {
+				location += fmt.Sprintf(":%d:%d", violation.Line, violation.Column)
+			}
+			cmd.Printf("%s: %s: %s (%s)\n",
+				location,
+				pointer,
+				violation.Message,
+				violation.Keyword,
+			)
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("project validation failed (%d errors)", len(violations))
+	}
+
+	return nil
+}
+
+func validateReportJson(out io.Writer, violations []manala.Violation) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(violations)
+}
+
+// See: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+func validateReportSarif(out io.Writer, violations []manala.Violation, version string) error {
+	results := []map[string]interface{}{}
+	for _, violation := range violations {
+		location := map[string]interface{}{
+			"logicalLocations": []interface{}{
+				map[string]interface{}{
+					"fullyQualifiedName": violation.Pointer,
+				},
+			},
+		}
+		// Set values violations are not located in any file
+		if violation.File != "" {
+			physicalLocation := map[string]interface{}{
+				"artifactLocation": map[string]interface{}{
+					"uri": filepath.ToSlash(violation.File),
+				},
+			}
+			// Regions require at least a start line
+			if violation.Line > 0 {
+				physicalLocation["region"] = map[string]interface{}{
+					"startLine":   violation.Line,
+					"startColumn": violation.Column,
+				}
+			}
+			location["physicalLocation"] = physicalLocation
+		}
+		results = append(results, map[string]interface{}{
+			"ruleId": violation.Keyword,
+			"level":  "error",
+			"message": map[string]interface{}{
+				"text": violation.Message,
+			},
+			"locations": []interface{}{location},
+		})
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "manala",
+						"version":        version,
+						"informationUri": "https://github.com/manala/manala",
+					},
+				},
+				"results": results,
+			},
+		},
+	})
+}
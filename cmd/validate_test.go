@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"manala/manala"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/********************/
+/* Validate - Suite */
+/********************/
+
+type ValidateTestSuite struct {
+	suite.Suite
+	wd string
+}
+
+func TestValidateTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(ValidateTestSuite))
+}
+
+func (s *ValidateTestSuite) SetupSuite() {
+	// Current working directory
+	s.wd, _ = os.Getwd()
+	// Default repository
+	viper.SetDefault(
+		"repository",
+		filepath.Join(s.wd, "testdata/validate/repository/default"),
+	)
+}
+
+func (s *ValidateTestSuite) ExecuteCmd(dir string, args []string) (*bytes.Buffer, *bytes.Buffer, error) {
+	if dir != "" {
+		_ = os.Chdir(dir)
+	}
+
+	// Command
+	cmd := ValidateCmd()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	stdOut := bytes.NewBufferString("")
+	cmd.SetOut(stdOut)
+	stdErr := bytes.NewBufferString("")
+	cmd.SetErr(stdErr)
+
+	log.SetHandler(cli.New(cmd.ErrOrStderr()))
+
+	err := cmd.Execute()
+
+	if dir != "" {
+		_ = os.Chdir(s.wd)
+	}
+
+	return stdOut, stdErr, err
+}
+
+/********************/
+/* Validate - Tests */
+/********************/
+
+func (s *ValidateTestSuite) TestValid() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/valid"},
+	)
+	s.NoError(err)
+	s.Equal("", stdOut.String())
+	s.Equal(`   • Project loaded            recipe=foo repository=
+   • Repository loaded        
+   • Recipe loaded            
+   • Project validated        
+`, stdErr.String())
+}
+
+func (s *ValidateTestSuite) TestInvalid() {
+	stdOut, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/invalid"},
+	)
+	s.Error(err)
+	s.Equal("project validation failed (2 errors)", err.Error())
+	s.Equal(`testdata/validate/project/invalid/.manala.yaml:4:1: /foo: foo must be one of the following: "bar", "baz" (enum)
+testdata/validate/project/invalid/.manala.yaml:5:1: /bar: Additional property bar is not allowed (additionalProperties)
+`, stdOut.String())
+}
+
+func (s *ValidateTestSuite) TestInvalidLayers() {
+	stdOut, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/valid", "--values", "testdata/validate/values.yaml"},
+	)
+	s.Error(err)
+	s.Equal("project validation failed (1 errors)", err.Error())
+	s.Equal(`testdata/validate/values.yaml:2:1: /foo: foo must be one of the following: "bar", "baz" (enum)
+`, stdOut.String())
+
+	stdOut, _, err = s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/valid", "--values", "testdata/validate/values.yaml", "--set", "foo=3"},
+	)
+	s.Error(err)
+	s.Equal(`--set: /foo: foo must be one of the following: "bar", "baz" (enum)
+`, stdOut.String())
+}
+
+func (s *ValidateTestSuite) TestInvalidJson() {
+	stdOut, _, err := s.ExecuteCmd(
+		"testdata/validate/project/invalid",
+		[]string{"--format", "json"},
+	)
+	s.Error(err)
+	s.JSONEq(`[
+  {"file": ".manala.yaml", "pointer": "/foo", "keyword": "enum", "message": "foo must be one of the following: \"bar\", \"baz\"", "line": 4, "column": 1},
+  {"file": ".manala.yaml", "pointer": "/bar", "keyword": "additionalProperties", "message": "Additional property bar is not allowed", "line": 5, "column": 1}
+]`, stdOut.String())
+}
+
+func (s *ValidateTestSuite) TestInvalidSarif() {
+	stdOut, _, err := s.ExecuteCmd(
+		"testdata/validate/project/invalid",
+		[]string{"--format", "sarif"},
+	)
+	s.Error(err)
+	s.Contains(stdOut.String(), `"version": "2.1.0"`)
+	s.Contains(stdOut.String(), `"ruleId": "enum"`)
+	s.Contains(stdOut.String(), `"uri": ".manala.yaml"`)
+	s.Contains(stdOut.String(), `"startLine": 4`)
+}
+
+func (s *ValidateTestSuite) TestReportSarifWithoutLine() {
+	stdOut := bytes.NewBufferString("")
+	err := validateReportSarif(stdOut, []manala.Violation{
+		{File: ".manala.yaml", Pointer: "/foo", Keyword: "required", Message: "foo is required"},
+	}, "1.0.0")
+	s.NoError(err)
+	s.Contains(stdOut.String(), `"uri": ".manala.yaml"`)
+	s.NotContains(stdOut.String(), `"region"`)
+}
+
+func (s *ValidateTestSuite) TestValidJson() {
+	stdOut, _, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/valid", "--format", "json"},
+	)
+	s.NoError(err)
+	s.Equal("[]\n", stdOut.String())
+}
+
+func (s *ValidateTestSuite) TestInvalidFormat() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/valid", "--format", "foo"},
+	)
+	s.Error(err)
+	s.Equal("invalid format: foo", err.Error())
+	s.Equal("", stdOut.String())
+	s.Equal("", stdErr.String())
+}
+
+func (s *ValidateTestSuite) TestNotFound() {
+	stdOut, stdErr, err := s.ExecuteCmd(
+		"",
+		[]string{"testdata/validate/project/not_found"},
+	)
+	s.Error(err)
+	s.Equal("project not found: testdata/validate/project/not_found", err.Error())
+	s.Equal("", stdOut.String())
+	s.Equal("", stdErr.String())
+}
+
+func (s *ValidateTestSuite) TestRecursive() {
+	stdOut, _, err := s.ExecuteCmd(
+		"testdata/validate/project/recursive",
+		[]string{"--recursive"},
+	)
+	s.Error(err)
+	s.Equal("project validation failed (2 errors)", err.Error())
+	s.Equal(`bar/.manala.yaml:4:1: /foo: foo must be one of the following: "bar", "baz" (enum)
+bar/.manala.yaml:5:1: /bar: Additional property bar is not allowed (additionalProperties)
+`, stdOut.String())
+}
@@ -32,6 +32,7 @@ Example: manala watch -> resulting in a watch in a directory (default to the cur
 
 	addRepositoryFlag(cmd, "force repository")
 	addRecipeFlag(cmd, "force recipe")
+	addValuesFlags(cmd)
 
 	cmd.Flags().BoolP("all", "a", false, "watch recipe too")
 	cmd.Flags().BoolP("notify", "n", false, "use system notifications")
@@ -55,11 +56,14 @@ func watchRun(cmd *cobra.Command, args []string) error {
 	repoLoader := loaders.NewRepositoryLoader(
 		viper.GetString("cache_dir"),
 		viper.GetString("repository"),
+		log.Log,
 	)
-	recLoader := loaders.NewRecipeLoader()
+	recLoader := loaders.NewRecipeLoader(cmd.Root().Version, log.Log)
 	repoName, _ := cmd.Flags().GetString("repository")
 	recName, _ := cmd.Flags().GetString("recipe")
-	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, repoName, recName)
+	valuesFiles, _ := cmd.Flags().GetStringSlice("values")
+	setValues, _ := cmd.Flags().GetStringArray("set")
+	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, repoName, recName, valuesFiles, setValues, log.Log)
 
 	// Directory
 	dir := "."
@@ -84,7 +88,7 @@ func watchRun(cmd *cobra.Command, args []string) error {
 	var prj models.ProjectInterface
 
 	// Get sync function
-	syncProject := watchSyncProjectFunc(prjFile, &prj, prjLoader, watcher, watchAll)
+	syncProject := watchSyncProjectFunc(prjFile, &prj, prjLoader, watcher, watchAll, cmd.Root().Version)
 
 	// Sync
 	if err := syncProject(); err != nil {
@@ -96,6 +100,17 @@ func watchRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error adding project watching: %v", err)
 	}
 
+	// Watch project config files outside project directory, such as values ones
+	configFiles := prjLoader.ConfigFiles(prjFile)
+	for _, file := range configFiles {
+		if filepath.Dir(file) == prj.Dir() {
+			continue
+		}
+		if err := watcher.Add(file); err != nil {
+			return fmt.Errorf("error adding project values watching: %v", err)
+		}
+	}
+
 	log.Info("Start watching...")
 
 	done := make(chan bool)
@@ -113,7 +128,7 @@ func watchRun(cmd *cobra.Command, args []string) error {
 					modified := false
 					file := filepath.Clean(event.Name)
 					dir := filepath.Dir(file)
-					if file == prjFile.Name() {
+					if watchIsConfigFile(file, configFiles) {
 						log.WithField("file", file).Info("Project config modified")
 						modified = true
 					} else if dir != prj.Dir() {
@@ -148,7 +163,16 @@ func watchRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func watchSyncProjectFunc(file *os.File, basePrj *models.ProjectInterface, prjLoader loaders.ProjectLoaderInterface, watcher *fsnotify.Watcher, watchAll bool) func() error {
+func watchIsConfigFile(file string, configFiles []string) bool {
+	for _, configFile := range configFiles {
+		if file == configFile {
+			return true
+		}
+	}
+	return false
+}
+
+func watchSyncProjectFunc(file *os.File, basePrj *models.ProjectInterface, prjLoader loaders.ProjectLoaderInterface, watcher *fsnotify.Watcher, watchAll bool, version string) func() error {
 	var baseRecDir string
 
 	return func() error {
@@ -201,7 +225,7 @@ func watchSyncProjectFunc(file *os.File, basePrj *models.ProjectInterface, prjLo
 		}
 
 		// Sync project
-		if err := syncer.SyncProject(prj); err != nil {
+		if err := syncer.SyncProject(prj, version); err != nil {
 			return err
 		}
 
@@ -0,0 +1,364 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/apex/log"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var recipeConfigFile = ".manala.yaml"
+
+// Extract project paths into a recipe directory.
+// Files containing vars literal values are turned into templates, and recipe config
+// file is written, with a sync unit per path, and vars as default ones.
+func Extract(src string, dst string, description string, paths []string, vars map[string]interface{}, logger log.Interface) error {
+	if _, ok := vars["manala"]; ok {
+		return fmt.Errorf("invalid var: manala")
+	}
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	literals, err := varsLiterals(vars, "")
+	if err != nil {
+		return err
+	}
+
+	// Same literal values could not be told apart
+	literalsMap := make(map[string]bool)
+	for _, literal := range literals {
+		if literalsMap[literal.value] {
+			return fmt.Errorf("duplicate literal value \"%s\"", literal.value)
+		}
+		literalsMap[literal.value] = true
+	}
+
+	// Longest literals first, so that they are not partially replaced by shorter ones
+	sort.SliceStable(literals, func(i, j int) bool {
+		return len(literals[i].value) > len(literals[j].value)
+	})
+	replacer := &literalsReplacer{literals: literals}
+
+	var syncUnits []string
+
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if filepath.IsAbs(path) || path == "." || strings.HasPrefix(path, ".."+string(filepath.Separator)) || path == ".." {
+			return fmt.Errorf("invalid path: %s", path)
+		}
+
+		stat, err := os.Stat(filepath.Join(src, path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("path not found: %s", path)
+			}
+			return err
+		}
+
+		var unit string
+		if stat.IsDir() {
+			unit, err = extractDir(src, dst, path, replacer, logger)
+		} else {
+			unit, err = extractFile(src, dst, path, stat.Mode(), replacer, logger)
+		}
+		if err != nil {
+			return err
+		}
+
+		syncUnits = append(syncUnits, unit)
+	}
+
+	return writeConfig(dst, description, syncUnits, vars)
+}
+
+// Extract a directory, recursively, and get its sync unit source
+func extractDir(src string, dst string, path string, replacer *literalsReplacer, logger log.Interface) (string, error) {
+	err := filepath.Walk(filepath.Join(src, path), func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+
+		_, err = extractFile(src, dst, rel, info.Mode(), replacer, logger)
+
+		return err
+	})
+
+	return filepath.ToSlash(path), err
+}
+
+// Extract a file, and get its sync unit source
+func extractFile(src string, dst string, path string, mode os.FileMode, replacer *literalsReplacer, logger log.Interface) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(src, path))
+	if err != nil {
+		return "", err
+	}
+
+	// Binary files are kept as is
+	replacements := 0
+	if !bytes.Contains(content, []byte{0}) {
+		var replaced string
+		if replaced, replacements = replacer.Replace(string(content)); replacements > 0 {
+			path += ".tmpl"
+			content = []byte(replaced)
+		}
+	}
+
+	logger.WithFields(log.Fields{
+		"path":         path,
+		"replacements": replacements,
+	}).Debug("Extracting file...")
+
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dst, path)), 0755); err != nil {
+		return "", err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dst, path), content, mode.Perm()); err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(path), nil
+}
+
+// Literals replacer, replacing literals by their template actions, and escaping existing template delimiters.
+// Literals are only replaced as whole tokens, that is, when they do not extend a word or a number, so that
+// "app" replaces neither "application" nor "apps", while "8.0" still replaces "php8.0".
+type literalsReplacer struct {
+	literals []literal // Longest first
+}
+
+// Replace content literals, and get the number of replacements
+func (r *literalsReplacer) Replace(content string) (string, int) {
+	var buf strings.Builder
+	replacements := 0
+
+	for i := 0; i < len(content); {
+		if strings.HasPrefix(content[i:], "{{") {
+			buf.WriteString(`{{ "{{" }}`)
+			i += 2
+			continue
+		}
+
+		replaced := false
+		for _, literal := range r.literals {
+			end := i + len(literal.value)
+			if !strings.HasPrefix(content[i:], literal.value) {
+				continue
+			}
+			if i > 0 && tokenClass(content[i-1]) != 0 && tokenClass(content[i-1]) == tokenClass(content[i]) {
+				continue
+			}
+			if end < len(content) && tokenClass(content[end]) != 0 && tokenClass(content[end]) == tokenClass(content[end-1]) {
+				continue
+			}
+			buf.WriteString("{{ " + literal.action + " }}")
+			replacements++
+			i = end
+			replaced = true
+			break
+		}
+
+		if !replaced {
+			buf.WriteByte(content[i])
+			i++
+		}
+	}
+
+	return buf.String(), replacements
+}
+
+// Get a character token class, either word (1), number (2), or none (0)
+func tokenClass(c byte) int {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return 1
+	case c >= '0' && c <= '9':
+		return 2
+	}
+	return 0
+}
+
+// Write recipe config file
+func writeConfig(dst string, description string, syncUnits []string, vars map[string]interface{}) error {
+	content, err := encodeYaml(map[string]interface{}{
+		"manala": map[string]interface{}{
+			"description": description,
+			"sync":        syncUnits,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(vars) > 0 {
+		varsContent, err := encodeYaml(vars)
+		if err != nil {
+			return err
+		}
+		content = append(append(content, '\n'), varsContent...)
+	}
+
+	return ioutil.WriteFile(filepath.Join(dst, recipeConfigFile), content, 0666)
+}
+
+func encodeYaml(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Var literal value, along with its template action
+type literal struct {
+	value  string
+	action string
+}
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Get vars literal values, recursively
+func varsLiterals(vars map[string]interface{}, path string) ([]literal, error) {
+	var keys []string
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var literals []literal
+
+	for _, key := range keys {
+		keyPath := path + "/" + key
+
+		switch value := vars[key].(type) {
+		case map[string]interface{}:
+			subLiterals, err := varsLiterals(value, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			literals = append(literals, subLiterals...)
+		case string:
+			if value == "" {
+				return nil, fmt.Errorf("empty literal value at \"%s\"", keyPath)
+			}
+			literals = append(literals, literal{
+				value:  value,
+				action: varAction(strings.Split(strings.TrimPrefix(keyPath, "/"), "/")),
+			})
+		default:
+			return nil, fmt.Errorf("invalid literal value at \"%s\"", keyPath)
+		}
+	}
+
+	return literals, nil
+}
+
+// Get var template action, falling back to index function for non identifier keys
+func varAction(keys []string) string {
+	for _, key := range keys {
+		if !identifierRegex.MatchString(key) {
+			var quoted []string
+			for _, key := range keys {
+				quoted = append(quoted, strconv.Quote(key))
+			}
+			return "index .Vars " + strings.Join(quoted, " ")
+		}
+	}
+
+	return ".Vars." + strings.Join(keys, ".")
+}
+
+// Parse vars mapping content, keeping literal values as they are written
+func ParseVars(content []byte) (map[string]interface{}, error) {
+	node := yaml.Node{}
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("invalid vars mapping (%w)", err)
+	}
+
+	if len(node.Content) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	return parseVarsNode(node.Content[0], "")
+}
+
+func parseVarsNode(node *yaml.Node, path string) (map[string]interface{}, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid vars mapping (line %d)", node.Line)
+	}
+
+	vars := map[string]interface{}{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch value.Kind {
+		case yaml.ScalarNode:
+			vars[key] = value.Value
+		case yaml.MappingNode:
+			subVars, err := parseVarsNode(value, path+"/"+key)
+			if err != nil {
+				return nil, err
+			}
+			vars[key] = subVars
+		default:
+			return nil, fmt.Errorf("invalid literal value at \"%s\" (line %d)", path+"/"+key, value.Line)
+		}
+	}
+
+	return vars, nil
+}
+
+// Set a var, at a dot separated path
+func SetVar(vars map[string]interface{}, path string, value string) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		if key == "" {
+			return fmt.Errorf("invalid var path: %s", path)
+		}
+		sub, ok := vars[key]
+		if !ok {
+			sub = map[string]interface{}{}
+			vars[key] = sub
+		}
+		subVars, ok := sub.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid var path: %s", path)
+		}
+		vars = subVars
+	}
+
+	key := keys[len(keys)-1]
+	if key == "" {
+		return fmt.Errorf("invalid var path: %s", path)
+	}
+	if _, ok := vars[key].(map[string]interface{}); ok {
+		return fmt.Errorf("invalid var path: %s", path)
+	}
+	vars[key] = value
+
+	return nil
+}
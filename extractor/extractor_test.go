@@ -0,0 +1,202 @@
+package extractor
+
+import (
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"manala/syncer"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*******************/
+/* Extract - Suite */
+/*******************/
+
+type ExtractTestSuite struct {
+	suite.Suite
+	dir    string
+	logger log.Interface
+}
+
+func TestExtractTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(ExtractTestSuite))
+}
+
+func (s *ExtractTestSuite) SetupTest() {
+	s.dir, _ = ioutil.TempDir("", "manala-extract")
+	s.logger = &log.Logger{Handler: discard.Default}
+}
+
+func (s *ExtractTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.dir)
+}
+
+/*******************/
+/* Extract - Tests */
+/*******************/
+
+func (s *ExtractTestSuite) TestExtract() {
+	content, _ := ioutil.ReadFile("testdata/vars.yaml")
+	vars, err := ParseVars(content)
+	s.NoError(err)
+
+	dst := filepath.Join(s.dir, "repository", "foo")
+	err = Extract("testdata/project", dst, "Foo", []string{"Makefile", ".docker"}, vars, s.logger)
+	s.NoError(err)
+
+	content, _ = ioutil.ReadFile(filepath.Join(dst, ".manala.yaml"))
+	s.Equal(`manala:
+    description: Foo
+    sync:
+        - Makefile.tmpl
+        - .docker
+
+app:
+    name: my-app
+php_version: "8.0"
+`, string(content))
+
+	content, _ = ioutil.ReadFile(filepath.Join(dst, "Makefile.tmpl"))
+	s.Equal(`APP = {{ .Vars.app.name }}
+PHP = php{{ .Vars.php_version }}
+
+build:
+	docker build -t {{ .Vars.app.name }} .
+`, string(content))
+
+	content, _ = ioutil.ReadFile(filepath.Join(dst, ".docker/Dockerfile.tmpl"))
+	s.Equal(`FROM php:{{ .Vars.php_version }}-fpm
+LABEL name="{{ "{{" }} app }}"
+`, string(content))
+
+	// Files without literal values are kept as is
+	s.FileExists(filepath.Join(dst, ".docker/conf/nginx.conf"))
+}
+
+func (s *ExtractTestSuite) TestExtractSync() {
+	content, _ := ioutil.ReadFile("testdata/vars.yaml")
+	vars, _ := ParseVars(content)
+
+	dst := filepath.Join(s.dir, "repository", "foo")
+	s.NoError(Extract("testdata/project", dst, "Foo", []string{"Makefile", ".docker"}, vars, s.logger))
+
+	// Recipe synced with its default vars gives the original project back
+	repo := models.NewRepository(filepath.Join(s.dir, "repository"), filepath.Join(s.dir, "repository"))
+	rec, err := loaders.NewRecipeLoader("", log.Log).Load("foo", repo)
+	s.NoError(err)
+
+	prjDir := filepath.Join(s.dir, "project")
+	_ = os.Mkdir(prjDir, 0755)
+	s.NoError(syncer.SyncProject(models.NewProject(prjDir, rec), ""))
+
+	for _, file := range []string{"Makefile", ".docker/Dockerfile", ".docker/conf/nginx.conf"} {
+		expected, _ := ioutil.ReadFile(filepath.Join("testdata/project", file))
+		actual, _ := ioutil.ReadFile(filepath.Join(prjDir, file))
+		s.Equal(string(expected), string(actual))
+	}
+}
+
+func (s *ExtractTestSuite) TestExtractIndex() {
+	dst := filepath.Join(s.dir, "foo")
+	err := Extract("testdata/project", dst, "Foo", []string{"Makefile"}, map[string]interface{}{
+		"app-name": "my-app",
+	}, s.logger)
+	s.NoError(err)
+
+	content, _ := ioutil.ReadFile(filepath.Join(dst, "Makefile.tmpl"))
+	s.Contains(string(content), `APP = {{ index .Vars "app-name" }}`)
+}
+
+func (s *ExtractTestSuite) TestExtractTokens() {
+	dst := filepath.Join(s.dir, "foo")
+	err := Extract("testdata/project", dst, "Foo", []string{"Makefile"}, map[string]interface{}{
+		"app": "my",
+		"cmd": "do",
+	}, s.logger)
+	s.NoError(err)
+
+	// Literals only replace whole tokens, "do" leaving "docker" untouched
+	content, _ := ioutil.ReadFile(filepath.Join(dst, "Makefile.tmpl"))
+	s.Equal(`APP = {{ .Vars.app }}-app
+PHP = php8.0
+
+build:
+	docker build -t {{ .Vars.app }}-app .
+`, string(content))
+}
+
+func (s *ExtractTestSuite) TestExtractErrors() {
+	for _, t := range []struct {
+		test  string
+		paths []string
+		vars  map[string]interface{}
+		err   string
+	}{
+		{
+			test:  "Path not found",
+			paths: []string{"not_found"},
+			err:   "path not found: not_found",
+		},
+		{
+			test:  "Path outside",
+			paths: []string{"../vars.yaml"},
+			err:   "invalid path: ../vars.yaml",
+		},
+		{
+			test: "Duplicate literal value",
+			vars: map[string]interface{}{"foo": "bar", "baz": "bar"},
+			err:  "duplicate literal value \"bar\"",
+		},
+		{
+			test: "Duplicate literal value, not adjacent",
+			vars: map[string]interface{}{"a": "xx", "b": "yy", "c": "xx"},
+			err:  "duplicate literal value \"xx\"",
+		},
+		{
+			test: "Empty literal value",
+			vars: map[string]interface{}{"foo": map[string]interface{}{"bar": ""}},
+			err:  "empty literal value at \"/foo/bar\"",
+		},
+		{
+			test: "Manala var",
+			vars: map[string]interface{}{"manala": "foo"},
+			err:  "invalid var: manala",
+		},
+	} {
+		s.Run(t.test, func() {
+			err := Extract("testdata/project", filepath.Join(s.dir, "foo"), "Foo", t.paths, t.vars, s.logger)
+			s.Error(err)
+			s.Equal(t.err, err.Error())
+		})
+	}
+}
+
+func (s *ExtractTestSuite) TestParseVarsErrors() {
+	_, err := ParseVars([]byte("foo: [bar]"))
+	s.Error(err)
+	s.Equal("invalid literal value at \"/foo\" (line 1)", err.Error())
+
+	_, err = ParseVars([]byte("- foo"))
+	s.Error(err)
+	s.Equal("invalid vars mapping (line 1)", err.Error())
+}
+
+func (s *ExtractTestSuite) TestSetVar() {
+	vars := map[string]interface{}{}
+	s.NoError(SetVar(vars, "foo.bar", "baz"))
+	s.NoError(SetVar(vars, "qux", "quux"))
+	s.Equal(map[string]interface{}{
+		"foo": map[string]interface{}{"bar": "baz"},
+		"qux": "quux",
+	}, vars)
+
+	s.Error(SetVar(vars, "foo", "bar"))
+	s.Error(SetVar(vars, "qux.bar", "baz"))
+	s.Error(SetVar(vars, "foo..bar", "baz"))
+}
@@ -0,0 +1,382 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/apex/log"
+	structValidator "github.com/go-playground/validator/v10"
+	"github.com/xeipuuv/gojsonpointer"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"manala/syncer"
+	"manala/validator"
+	"manala/yaml/doc"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Recipe lint problem
+type Problem struct {
+	Recipe  string `json:"recipe"`
+	Message string `json:"message"`
+}
+
+// Lint all repository recipes, reporting all their problems
+func LintRepository(recLoader loaders.RecipeLoaderInterface, repo models.RepositoryInterface, version string, logger log.Interface) ([]Problem, error) {
+	files, err := ioutil.ReadDir(repo.Dir())
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []Problem{}
+
+	for _, file := range files {
+		// Exclude non recipe files, just like recipe loader does
+		if !file.IsDir() || !loaders.IsRecipeName(file.Name()) {
+			continue
+		}
+		problems = append(problems, LintRecipe(recLoader, file.Name(), repo, version, logger)...)
+	}
+
+	return problems, nil
+}
+
+// Lint a repository recipe, reporting all its problems.
+// Recipe config is parsed by the linter itself, so that all its tags problems
+// are reported at once, and other checks still run on the remaining config.
+func LintRecipe(recLoader loaders.RecipeLoaderInterface, name string, repo models.RepositoryInterface, version string, logger log.Interface) []Problem {
+	lnt := &linter{
+		recipe:   name,
+		problems: []Problem{},
+		logger:   logger,
+	}
+
+	lnt.logger.WithField("name", name).Debug("Linting recipe...")
+
+	// Exclude non recipe names, just like recipe loader does
+	if !loaders.IsRecipeName(name) {
+		lnt.report("recipe not found")
+		return lnt.problems
+	}
+
+	dir := filepath.Join(repo.Dir(), name)
+
+	recFile, err := recLoader.Find(dir)
+	if err != nil {
+		lnt.report(err.Error())
+		return lnt.problems
+	}
+	if recFile == nil {
+		lnt.report("recipe not found")
+		return lnt.problems
+	}
+	defer recFile.Close()
+
+	node := yaml.Node{}
+	if err := yaml.NewDecoder(recFile).Decode(&node); err != nil {
+		if err == io.EOF {
+			lnt.report(fmt.Sprintf("empty recipe config \"%s\"", recFile.Name()))
+		} else {
+			lnt.report(fmt.Sprintf("invalid recipe config \"%s\" (%s)", recFile.Name(), err))
+		}
+		return lnt.problems
+	}
+
+	lnt.lintConfigTags(&node, "")
+	lnt.lintRequires(&node)
+
+	// Load what remains of the config once its problems are left out
+	rec, err := recLoader.LoadNode(name, recFile.Name(), &node, repo)
+	if err != nil {
+		lnt.report(err.Error())
+		lnt.lintTemplates(dir)
+		return lnt.problems
+	}
+
+	lnt.lintSyncUnits(rec, version)
+	lnt.lintTemplates(rec.Dir())
+	lnt.lintSchema(rec.Schema(), rec.Vars(), true, "/")
+	lnt.lintOptions(rec)
+
+	return lnt.problems
+}
+
+type linter struct {
+	recipe   string
+	problems []Problem
+	logger   log.Interface
+}
+
+// Report a problem, only once
+func (lnt *linter) report(message string) {
+	for _, problem := range lnt.problems {
+		if problem.Message == message {
+			return
+		}
+	}
+
+	lnt.problems = append(lnt.problems, Problem{
+		Recipe:  lnt.recipe,
+		Message: message,
+	})
+}
+
+// Config tags must be valid, invalid ones being removed from their comments
+func (lnt *linter) lintConfigTags(node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			lnt.lintConfigTags(child, "/")
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := filepath.Join(path, key.Value)
+
+			// Exclude "manala" config
+			if keyPath == "/manala" {
+				continue
+			}
+
+			if key.HeadComment != "" {
+				lnt.lintConfigTag(key, keyPath)
+			}
+
+			lnt.lintConfigTags(value, keyPath)
+		}
+	}
+}
+
+func (lnt *linter) lintConfigTag(key *yaml.Node, path string) {
+	tags := doc.ParseCommentTags(key.HeadComment)
+
+	var valid []*doc.Tag
+	for _, tag := range tags.All() {
+		var err error
+		switch tag.Name {
+		case "infer":
+			if _, e := strconv.ParseBool(tag.Value); e != nil {
+				err = fmt.Errorf("invalid recipe infer tag at \"%s\": %w", path, e)
+			}
+		case "schema":
+			var schema map[string]interface{}
+			if e := json.Unmarshal([]byte(tag.Value), &schema); e != nil {
+				err = fmt.Errorf("invalid recipe schema tag at \"%s\": %w", path, e)
+			}
+		case "option":
+			option := &models.RecipeOption{}
+			if e := json.Unmarshal([]byte(tag.Value), &option); e != nil {
+				err = fmt.Errorf("invalid recipe option tag at \"%s\": %w", path, e)
+			} else if e := structValidator.New().Struct(option); e != nil {
+				err = fmt.Errorf("incorrect recipe option tag at \"%s\": %w", path, e)
+			}
+		}
+		if err != nil {
+			lnt.report(err.Error())
+			continue
+		}
+		valid = append(valid, tag)
+	}
+
+	if len(valid) == len(tags.All()) {
+		return
+	}
+
+	// Rewrite comment, keeping its description and valid tags only
+	var lines []string
+	if description := doc.ParseCommentDescription(key.HeadComment); description != "" {
+		for _, line := range strings.Split(description, "\n") {
+			lines = append(lines, "# "+line)
+		}
+	}
+	for _, tag := range valid {
+		for i, line := range strings.Split(tag.Value, "\n") {
+			if i == 0 {
+				line = "@" + tag.Name + " " + line
+			}
+			lines = append(lines, "# "+line)
+		}
+	}
+	key.HeadComment = strings.Join(lines, "\n")
+}
+
+// Required manala version must be a valid constraint, invalid one being removed
+func (lnt *linter) lintRequires(node *yaml.Node) {
+	requires := configNode(node, "manala", "requires")
+	if requires == nil || requires.Kind != yaml.ScalarNode || requires.Value == "" {
+		return
+	}
+
+	if _, err := semver.NewConstraint(requires.Value); err != nil {
+		lnt.report(fmt.Sprintf("invalid recipe requires \"%s\" (%s)", requires.Value, err))
+		requires.Value = ""
+	}
+}
+
+// Get a config node by its mapping keys, if any
+func configNode(node *yaml.Node, keys ...string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+			}
+		}
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+
+	return node
+}
+
+// Sync units sources must exist, and render with recipe default vars
+func (lnt *linter) lintSyncUnits(rec models.RecipeInterface, version string) {
+	for _, unit := range rec.SyncUnits() {
+		if _, err := os.Stat(path.Join(rec.Dir(), unit.Source)); err != nil {
+			if os.IsNotExist(err) {
+				lnt.report(fmt.Sprintf("sync source \"%s\" does not exist", unit.Source))
+			} else {
+				lnt.report(err.Error())
+			}
+			continue
+		}
+
+		if err := lintSyncUnit(rec, unit, version, lnt.logger); err != nil {
+			lnt.report(err.Error())
+		}
+	}
+}
+
+// Sync a single unit into a temporary project, using recipe default vars
+func lintSyncUnit(rec models.RecipeInterface, unit models.RecipeSyncUnit, version string, logger log.Interface) error {
+	dir, err := ioutil.TempDir("", "manala-lint")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	unitRec := models.NewRecipe(rec.Name(), rec.Description(), rec.Dir(), rec.Repository())
+	vars := rec.Vars()
+	unitRec.MergeVars(&vars)
+	unitRec.AddSyncUnits([]models.RecipeSyncUnit{unit})
+	unitRec.AddEnv(rec.Env())
+	unitRec.SetNormalization(rec.Normalization())
+
+	_, err = syncer.SyncProjectOptions(models.NewProject(dir, unitRec), version, syncer.Options{
+		Logger: logger,
+	})
+
+	return err
+}
+
+// All recipe templates must parse, synced or not
+func (lnt *linter) lintTemplates(dir string) {
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// Exclude dot directories
+			if strings.HasPrefix(info.Name(), ".") && file != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(file, ".tmpl") {
+			if err := syncer.ParseTemplateFile(file); err != nil {
+				lnt.report(err.Error())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		lnt.report(err.Error())
+	}
+}
+
+// Schemas must be valid json schemas, and default values must be part of their enums
+func (lnt *linter) lintSchema(schema map[string]interface{}, value interface{}, isValue bool, pointer string) {
+	// Properties first
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		values, _ := value.(map[string]interface{})
+
+		var keys []string
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			propertyPointer := path.Join(pointer, key)
+			propertySchema, ok := properties[key].(map[string]interface{})
+			if !ok {
+				lnt.report(fmt.Sprintf("invalid schema at \"%s\" (not an object)", propertyPointer))
+				continue
+			}
+			propertyValue, isPropertyValue := values[key]
+			lnt.lintSchema(propertySchema, propertyValue, isValue && isPropertyValue, propertyPointer)
+		}
+	}
+
+	// Then schema itself, properties aside
+	ownSchema := map[string]interface{}{}
+	for keyword, v := range schema {
+		if keyword != "properties" {
+			ownSchema[keyword] = v
+		}
+	}
+
+	if _, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(ownSchema)); err != nil {
+		lnt.report(fmt.Sprintf("invalid schema at \"%s\" (%s)", pointer, err))
+		return
+	}
+
+	if enum, ok := schema["enum"]; ok && isValue {
+		if err := validator.ValidateValue(value, map[string]interface{}{"enum": enum}); err != nil {
+			lnt.report(fmt.Sprintf("default value at \"%s\" is not part of its enum", pointer))
+		}
+	}
+}
+
+// Options must be bound, the way init does, that is,
+// at a json pointer resolving in recipe vars, either by an enum or a string
+func (lnt *linter) lintOptions(rec models.RecipeInterface) {
+	for _, option := range rec.Options() {
+		pointer, err := gojsonpointer.NewJsonPointer(option.Path)
+		if err == nil {
+			_, _, err = pointer.Get(rec.Vars())
+		}
+		if err != nil {
+			lnt.report(fmt.Sprintf("option \"%s\" path \"%s\" does not resolve in recipe vars", option.Label, option.Path))
+		}
+
+		if enum, ok := option.Schema["enum"]; ok {
+			if values, ok := enum.([]interface{}); !ok || len(values) == 0 {
+				lnt.report(fmt.Sprintf("option \"%s\" enum is empty", option.Label))
+			}
+		} else if option.Schema["type"] != "string" {
+			lnt.report(fmt.Sprintf("option \"%s\" is neither an enum nor a string", option.Label))
+		}
+	}
+}
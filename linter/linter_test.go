@@ -0,0 +1,101 @@
+package linter
+
+import (
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stretchr/testify/suite"
+	"manala/loaders"
+	"manala/models"
+	"testing"
+)
+
+/****************/
+/* Lint - Suite */
+/****************/
+
+type LintTestSuite struct {
+	suite.Suite
+	repository models.RepositoryInterface
+	logger     log.Interface
+}
+
+func TestLintTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(LintTestSuite))
+}
+
+func (s *LintTestSuite) SetupTest() {
+	s.repository = models.NewRepository("testdata/repository", "testdata/repository")
+	s.logger = &log.Logger{Handler: discard.Default}
+}
+
+/****************/
+/* Lint - Tests */
+/****************/
+
+func (s *LintTestSuite) TestLintRecipeValid() {
+	problems := LintRecipe(loaders.NewRecipeLoader("", log.Log), "valid", s.repository, "", s.logger)
+	s.Empty(problems)
+}
+
+func (s *LintTestSuite) TestLintRecipeNotFound() {
+	problems := LintRecipe(loaders.NewRecipeLoader("", log.Log), "not_found", s.repository, "", s.logger)
+	s.Equal([]Problem{
+		{Recipe: "not_found", Message: "recipe not found"},
+	}, problems)
+}
+
+func (s *LintTestSuite) TestLintRecipeBroken() {
+	problems := LintRecipe(loaders.NewRecipeLoader("", log.Log), "broken", s.repository, "", s.logger)
+	s.Equal([]Problem{
+		{Recipe: "broken", Message: "invalid recipe config \"testdata/repository/broken/.manala.yaml\" (yaml: mapping values are not allowed in this context)"},
+	}, problems)
+}
+
+func (s *LintTestSuite) TestLintRecipeProblems() {
+	problems := LintRecipe(loaders.NewRecipeLoader("", log.Log), "problems", s.repository, "", s.logger)
+	s.Equal([]Problem{
+		// Config problems are all reported, other checks running on the remaining config
+		{Recipe: "problems", Message: `invalid recipe schema tag at "/qux": invalid character 'i' looking for beginning of object key string`},
+		{Recipe: "problems", Message: `incorrect recipe option tag at "/quux": Key: 'RecipeOption.Label' Error:Field validation for 'Label' failed on the 'required' tag`},
+		{Recipe: "problems", Message: `invalid recipe requires "foo" (improper constraint: foo)`},
+		{Recipe: "problems", Message: `sync source "not_found" does not exist`},
+		{Recipe: "problems", Message: `invalid template "testdata/repository/problems/missing_key.tmpl" at line 1, column 14 (at <.Vars.fo>: map has no entry for key "fo")
+1 | foo: {{ .Vars.fo }}
+  |              ^
+did you mean .Vars.foo?`},
+		// Synced templates parse errors are only reported once
+		{Recipe: "problems", Message: `invalid template "testdata/repository/problems/dir/parse_error.tmpl" at line 2 (unexpected "}" in operand)
+2 | bar: {{ .foo }`},
+		{Recipe: "problems", Message: `invalid template "testdata/repository/problems/unsynced.tmpl" at line 1 (unexpected "}" in operand)
+1 | foo: {{ .foo }`},
+		{Recipe: "problems", Message: `invalid schema at "/bar" (has a primitive type that is NOT VALID -- given: /foo/ Expected valid values are:[array boolean integer number null object string])`},
+		{Recipe: "problems", Message: `default value at "/foo" is not part of its enum`},
+		{Recipe: "problems", Message: `option "Corge" is neither an enum nor a string`},
+		{Recipe: "problems", Message: `option "Grault" path "/grault/garply" does not resolve in recipe vars`},
+	}, problems)
+}
+
+func (s *LintTestSuite) TestLintRecipeUndescribed() {
+	problems := LintRecipe(loaders.NewRecipeLoader("", log.Log), "undescribed", s.repository, "", s.logger)
+	s.Equal([]Problem{
+		{Recipe: "undescribed", Message: "Key: 'recipeConfig.Description' Error:Field validation for 'Description' failed on the 'required' tag"},
+		// Templates are still linted
+		{Recipe: "undescribed", Message: `invalid template "testdata/repository/undescribed/template.tmpl" at line 1 (unexpected "}" in operand)
+1 | foo: {{ .foo }`},
+	}, problems)
+}
+
+func (s *LintTestSuite) TestLintRepository() {
+	problems, err := LintRepository(loaders.NewRecipeLoader("", log.Log), s.repository, "", s.logger)
+	s.NoError(err)
+	s.Len(problems, 14)
+	s.Equal("broken", problems[0].Recipe)
+	s.Equal("problems", problems[1].Recipe)
+}
+
+func (s *LintTestSuite) TestLintRepositoryNotFound() {
+	problems, err := LintRepository(loaders.NewRecipeLoader("", log.Log), models.NewRepository("testdata/not_found", "testdata/not_found"), "", s.logger)
+	s.Error(err)
+	s.Nil(problems)
+}
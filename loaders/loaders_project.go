@@ -7,28 +7,38 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"gopkg.in/yaml.v3"
 	"io"
+	"io/ioutil"
 	"manala/models"
 	"manala/yaml/cleaner"
+	"manala/yaml/resolver"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
-func NewProjectLoader(repositoryLoader RepositoryLoaderInterface, recipeLoader RecipeLoaderInterface, forceRepositorySrc string, forceRecipe string) ProjectLoaderInterface {
+func NewProjectLoader(repositoryLoader RepositoryLoaderInterface, recipeLoader RecipeLoaderInterface, forceRepositorySrc string, forceRecipe string, valuesFiles []string, setValues []string, logger log.Interface) ProjectLoaderInterface {
 	return &projectLoader{
 		repositoryLoader:   repositoryLoader,
 		recipeLoader:       recipeLoader,
 		forceRepositorySrc: forceRepositorySrc,
 		forceRecipe:        forceRecipe,
+		valuesFiles:        valuesFiles,
+		setValues:          setValues,
+		logger:             logger,
 	}
 }
 
 type ProjectLoaderInterface interface {
 	Find(dir string, traverse bool) (*os.File, error)
 	Load(file *os.File) (models.ProjectInterface, error)
+	ConfigFiles(file *os.File) []string
+	Layers(file *os.File) ([]models.ProjectLayer, error)
 }
 
 var projectConfigFile = ".manala.yaml"
+var projectLocalConfigFile = ".manala.local.yaml"
+var projectSecretsFile = ".manala.env"
 
 type projectConfig struct {
 	Recipe     string `validate:"required"`
@@ -40,10 +50,13 @@ type projectLoader struct {
 	recipeLoader       RecipeLoaderInterface
 	forceRepositorySrc string
 	forceRecipe        string
+	valuesFiles        []string
+	setValues          []string
+	logger             log.Interface
 }
 
 func (ld *projectLoader) Find(dir string, traverse bool) (*os.File, error) {
-	log.WithField("dir", dir).Debug("Searching project...")
+	ld.logger.WithField("dir", dir).Debug("Searching project...")
 
 	file, err := os.Open(path.Join(dir, projectConfigFile))
 
@@ -81,7 +94,7 @@ func (ld *projectLoader) Load(file *os.File) (models.ProjectInterface, error) {
 	// Get dir
 	dir := filepath.Dir(file.Name())
 
-	log.WithField("dir", dir).Debug("Loading project...")
+	ld.logger.WithField("dir", dir).Debug("Loading project...")
 
 	// Reset file pointer
 	_, err := file.Seek(0, io.SeekStart)
@@ -89,15 +102,31 @@ func (ld *projectLoader) Load(file *os.File) (models.ProjectInterface, error) {
 		return nil, err
 	}
 
+	// Load secrets
+	secrets, err := loadProjectSecretsFile(filepath.Join(dir, projectSecretsFile))
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse config file
-	var vars map[string]interface{}
-	if err := yaml.NewDecoder(file).Decode(&vars); err != nil {
+	node := yaml.Node{}
+	if err := yaml.NewDecoder(file).Decode(&node); err != nil {
 		if err == io.EOF {
 			return nil, fmt.Errorf("empty project config \"%s\"", file.Name())
 		}
 		return nil, fmt.Errorf("invalid project config \"%s\" (%w)", file.Name(), err)
 	}
 
+	// Resolve env vars, secrets and files
+	if err := resolver.NewResolver(dir, os.LookupEnv, secrets, ld.logger).Resolve(&node); err != nil {
+		return nil, fmt.Errorf("invalid project config \"%s\" (%w)", file.Name(), err)
+	}
+
+	var vars map[string]interface{}
+	if err := node.Decode(&vars); err != nil {
+		return nil, fmt.Errorf("invalid project config \"%s\" (%w)", file.Name(), err)
+	}
+
 	// See: https://github.com/go-yaml/yaml/issues/139
 	vars = cleaner.Clean(vars)
 
@@ -129,7 +158,7 @@ func (ld *projectLoader) Load(file *os.File) (models.ProjectInterface, error) {
 	// Cleanup vars
 	delete(vars, "manala")
 
-	log.WithFields(log.Fields{
+	ld.logger.WithFields(log.Fields{
 		"recipe":     cfg.Recipe,
 		"repository": cfg.Repository,
 	}).Info("Project loaded")
@@ -140,14 +169,19 @@ func (ld *projectLoader) Load(file *os.File) (models.ProjectInterface, error) {
 		return nil, err
 	}
 
-	log.Info("Repository loaded")
+	ld.logger.Info("Repository loaded")
 
 	rec, err := ld.recipeLoader.Load(cfg.Recipe, repo)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Info("Recipe loaded")
+	// Projects are meant to be synced with their recipes
+	if err := ld.recipeLoader.CheckRequires(rec); err != nil {
+		return nil, err
+	}
+
+	ld.logger.Info("Recipe loaded")
 
 	prj := models.NewProject(
 		dir,
@@ -155,5 +189,177 @@ func (ld *projectLoader) Load(file *os.File) (models.ProjectInterface, error) {
 	)
 	prj.MergeVars(&vars)
 
+	// Layered values, deep merged in order: optional local config, values files, then set values
+	localValues, err := ld.loadValuesFile(filepath.Join(dir, projectLocalConfigFile), false, secrets)
+	if err != nil {
+		return nil, err
+	}
+	prj.MergeVars(&localValues)
+
+	for _, valuesFile := range ld.valuesFiles {
+		values, err := ld.loadValuesFile(valuesFile, true, secrets)
+		if err != nil {
+			return nil, err
+		}
+		prj.MergeVars(&values)
+	}
+
+	for _, setValue := range ld.setValues {
+		values, err := parseProjectSetValue(setValue)
+		if err != nil {
+			return nil, err
+		}
+		prj.MergeVars(&values)
+	}
+
 	return prj, nil
 }
+
+// Get all files a project config is made of, whether they exist or not,
+// in the case of local config and secrets files
+func (ld *projectLoader) ConfigFiles(file *os.File) []string {
+	files := []string{
+		file.Name(),
+		filepath.Join(filepath.Dir(file.Name()), projectLocalConfigFile),
+		filepath.Join(filepath.Dir(file.Name()), projectSecretsFile),
+	}
+
+	for _, valuesFile := range ld.valuesFiles {
+		files = append(files, filepath.Clean(valuesFile))
+	}
+
+	return files
+}
+
+// Get project config layers, in merge order, as raw documents, so that their values could be located
+func (ld *projectLoader) Layers(file *os.File) ([]models.ProjectLayer, error) {
+	var layers []models.ProjectLayer
+
+	files := append([]string{
+		file.Name(),
+		filepath.Join(filepath.Dir(file.Name()), projectLocalConfigFile),
+	}, ld.valuesFiles...)
+
+	for _, name := range files {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			// Local config is optional
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		node := yaml.Node{}
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return nil, fmt.Errorf("invalid project values \"%s\" (%w)", name, err)
+		}
+
+		layers = append(layers, models.ProjectLayer{File: name, Node: &node})
+	}
+
+	for _, setValue := range ld.setValues {
+		values, err := parseProjectSetValue(setValue)
+		if err != nil {
+			return nil, err
+		}
+
+		node := yaml.Node{}
+		if err := node.Encode(values); err != nil {
+			return nil, err
+		}
+
+		layers = append(layers, models.ProjectLayer{Node: &node})
+	}
+
+	return layers, nil
+}
+
+// Load secrets dotenv file, if any
+func loadProjectSecretsFile(name string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	secrets, err := resolver.ParseDotenv(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project secrets \"%s\" (%w)", name, err)
+	}
+
+	return secrets, nil
+}
+
+// Load a values file, optionally required to exist
+func (ld *projectLoader) loadValuesFile(name string, required bool, secrets map[string]string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return values, nil
+		}
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("project values not found: %s", name)
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	ld.logger.WithField("file", name).Debug("Merging project values...")
+
+	node := yaml.Node{}
+	if err := yaml.NewDecoder(file).Decode(&node); err != nil {
+		if err == io.EOF {
+			return values, nil
+		}
+		return nil, fmt.Errorf("invalid project values \"%s\" (%w)", name, err)
+	}
+
+	// Resolve env vars, secrets and files, relative to values file
+	if err := resolver.NewResolver(filepath.Dir(name), os.LookupEnv, secrets, ld.logger).Resolve(&node); err != nil {
+		return nil, fmt.Errorf("invalid project values \"%s\" (%w)", name, err)
+	}
+
+	if err := node.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid project values \"%s\" (%w)", name, err)
+	}
+
+	// Project config is only handled by main config file
+	if _, ok := values["manala"]; ok {
+		return nil, fmt.Errorf("invalid project values \"%s\" (reserved \"manala\" key)", name)
+	}
+
+	// See: https://github.com/go-yaml/yaml/issues/139
+	return cleaner.Clean(values), nil
+}
+
+// Parse a "key.path=value" set value into nested values,
+// value being decoded as yaml, so that it could be typed
+func parseProjectSetValue(setValue string) (map[string]interface{}, error) {
+	invalid := fmt.Errorf("invalid project set value \"%s\" (expected key.path=value)", setValue)
+
+	i := strings.Index(setValue, "=")
+	if i < 0 {
+		return nil, invalid
+	}
+
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(setValue[i+1:]), &value); err != nil {
+		return nil, fmt.Errorf("invalid project set value \"%s\" (%w)", setValue, err)
+	}
+
+	keys := strings.Split(setValue[:i], ".")
+	for j := len(keys) - 1; j >= 0; j-- {
+		if keys[j] == "" || (j == 0 && keys[j] == "manala") {
+			return nil, invalid
+		}
+		value = map[string]interface{}{keys[j]: value}
+	}
+
+	// See: https://github.com/go-yaml/yaml/issues/139
+	return cleaner.Clean(value.(map[string]interface{})), nil
+}
@@ -34,8 +34,9 @@ func (s *ProjectTestSuite) SetupTest() {
 	s.repositoryLoader = NewRepositoryLoader(
 		cacheDir,
 		"testdata/project/_repository_default",
+		log.Log,
 	)
-	s.recipeLoader = NewRecipeLoader()
+	s.recipeLoader = NewRecipeLoader("", log.Log)
 }
 
 /*******************/
@@ -43,7 +44,7 @@ func (s *ProjectTestSuite) SetupTest() {
 /*******************/
 
 func (s *ProjectTestSuite) TestProject() {
-	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 	s.Implements((*ProjectLoaderInterface)(nil), ld)
 }
 
@@ -64,7 +65,7 @@ func (s *ProjectTestSuite) TestProjectFind() {
 		},
 	} {
 		s.Run(t.test, func() {
-			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 			prjFile, err := ld.Find(t.dir, false)
 			s.NoError(err)
 			if t.prjFileName != "" {
@@ -99,7 +100,7 @@ func (s *ProjectTestSuite) TestProjectFindTraverse() {
 		},
 	} {
 		s.Run(t.test, func() {
-			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 			prjFile, err := ld.Find(t.dir, true)
 			s.NoError(err)
 			if t.prjFileName != "" {
@@ -150,7 +151,7 @@ func (s *ProjectTestSuite) TestProjectLoad() {
 		},
 	} {
 		s.Run(t.test, func() {
-			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, t.forceRepositorySrc, t.forceRecipe)
+			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, t.forceRepositorySrc, t.forceRecipe, nil, nil, log.Log)
 			prjFile, err := ld.Find("testdata/project/load", false)
 			s.NoError(err)
 			prj, err := ld.Load(prjFile)
@@ -164,7 +165,7 @@ func (s *ProjectTestSuite) TestProjectLoad() {
 }
 
 func (s *ProjectTestSuite) TestProjectLoadEmpty() {
-	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 	prjFile, err := ld.Find("testdata/project/load_empty", false)
 	s.NoError(err)
 	prj, err := ld.Load(prjFile)
@@ -174,7 +175,7 @@ func (s *ProjectTestSuite) TestProjectLoadEmpty() {
 }
 
 func (s *ProjectTestSuite) TestProjectLoadIncorrect() {
-	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 	prjFile, err := ld.Find("testdata/project/load_incorrect", false)
 	s.NoError(err)
 	prj, err := ld.Load(prjFile)
@@ -183,8 +184,18 @@ func (s *ProjectTestSuite) TestProjectLoadIncorrect() {
 	s.Nil(prj)
 }
 
+func (s *ProjectTestSuite) TestProjectLoadRequires() {
+	ld := NewProjectLoader(s.repositoryLoader, NewRecipeLoader("1.0.0", log.Log), "", "", nil, nil, log.Log)
+	prjFile, err := ld.Find("testdata/project/load_requires", false)
+	s.NoError(err)
+	prj, err := ld.Load(prjFile)
+	s.Error(err)
+	s.Equal("recipe \"requires\" requires manala >=2.0, current version is 1.0.0, please upgrade manala", err.Error())
+	s.Nil(prj)
+}
+
 func (s *ProjectTestSuite) TestProjectLoadNoRecipe() {
-	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 	prjFile, err := ld.Find("testdata/project/load_no_recipe", false)
 	s.NoError(err)
 	prj, err := ld.Load(prjFile)
@@ -231,7 +242,7 @@ func (s *ProjectTestSuite) TestProjectLoadRepository() {
 		},
 	} {
 		s.Run(t.test, func() {
-			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, t.forceRepositorySrc, t.forceRecipe)
+			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, t.forceRepositorySrc, t.forceRecipe, nil, nil, log.Log)
 			prjFile, err := ld.Find("testdata/project/load_repository", false)
 			s.NoError(err)
 			prj, err := ld.Load(prjFile)
@@ -245,7 +256,7 @@ func (s *ProjectTestSuite) TestProjectLoadRepository() {
 }
 
 func (s *ProjectTestSuite) TestProjectLoadVars() {
-	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "")
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
 	prjFile, err := ld.Find("testdata/project/load_vars", false)
 	s.NoError(err)
 	prj, err := ld.Load(prjFile)
@@ -261,3 +272,120 @@ func (s *ProjectTestSuite) TestProjectLoadVars() {
 		prj.Vars(),
 	)
 }
+
+func (s *ProjectTestSuite) TestProjectLoadValues() {
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "",
+		[]string{"testdata/project/load_values/values.yaml"},
+		[]string{"bar.baz=set", "baz.qux=123", "qux.quux=foo=bar"},
+		log.Log,
+	)
+	prjFile, err := ld.Find("testdata/project/load_values", false)
+	s.NoError(err)
+	prj, err := ld.Load(prjFile)
+	s.NoError(err)
+	s.Equal(
+		map[string]interface{}{
+			"foo": map[string]interface{}{"foo": "project", "bar": "local", "baz": []interface{}{"values"}},
+			"bar": map[string]interface{}{"bar": "values", "baz": "set"},
+			"baz": map[string]interface{}{"bar": "baz", "baz": "qux", "qux": 123},
+			"qux": map[string]interface{}{"quux": "foo=bar"},
+		},
+		prj.Vars(),
+	)
+	s.Equal([]string{
+		"testdata/project/load_values/.manala.yaml",
+		"testdata/project/load_values/.manala.local.yaml",
+		"testdata/project/load_values/.manala.env",
+		"testdata/project/load_values/values.yaml",
+	}, ld.ConfigFiles(prjFile))
+
+	layers, err := ld.Layers(prjFile)
+	s.NoError(err)
+	s.Len(layers, 6)
+	for i, file := range []string{
+		"testdata/project/load_values/.manala.yaml",
+		"testdata/project/load_values/.manala.local.yaml",
+		"testdata/project/load_values/values.yaml",
+		"", "", "",
+	} {
+		s.Equal(file, layers[i].File)
+		s.NotNil(layers[i].Node)
+	}
+}
+
+func (s *ProjectTestSuite) TestProjectLoadValuesErrors() {
+	for _, t := range []struct {
+		test        string
+		valuesFiles []string
+		setValues   []string
+		err         string
+	}{
+		{
+			test:        "Values not found",
+			valuesFiles: []string{"testdata/project/load_values/not_found.yaml"},
+			err:         "project values not found: testdata/project/load_values/not_found.yaml",
+		},
+		{
+			test:        "Values invalid",
+			valuesFiles: []string{"testdata/project/load_values/values_invalid.yaml"},
+			err:         "invalid project values \"testdata/project/load_values/values_invalid.yaml\" (yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `foo` into map[string]interface {})",
+		},
+		{
+			test:        "Values manala",
+			valuesFiles: []string{"testdata/project/load_values/values_manala.yaml"},
+			err:         "invalid project values \"testdata/project/load_values/values_manala.yaml\" (reserved \"manala\" key)",
+		},
+		{
+			test:      "Set without value",
+			setValues: []string{"foo"},
+			err:       "invalid project set value \"foo\" (expected key.path=value)",
+		},
+		{
+			test:      "Set empty key",
+			setValues: []string{"foo..bar=baz"},
+			err:       "invalid project set value \"foo..bar=baz\" (expected key.path=value)",
+		},
+		{
+			test:      "Set manala",
+			setValues: []string{"manala.recipe=bar"},
+			err:       "invalid project set value \"manala.recipe=bar\" (expected key.path=value)",
+		},
+	} {
+		s.Run(t.test, func() {
+			ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", t.valuesFiles, t.setValues, log.Log)
+			prjFile, _ := ld.Find("testdata/project/load_values", false)
+			prj, err := ld.Load(prjFile)
+			s.Error(err)
+			s.Equal(t.err, err.Error())
+			s.Nil(prj)
+		})
+	}
+}
+
+func (s *ProjectTestSuite) TestProjectLoadResolve() {
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
+	prjFile, err := ld.Find("testdata/project/load_resolve", false)
+	s.NoError(err)
+	prj, err := ld.Load(prjFile)
+	s.NoError(err)
+	s.Equal(
+		map[string]interface{}{"foo": "default", "bar": "s3cr3t", "baz": "baz"},
+		prj.Vars()["foo"],
+	)
+
+	_ = os.Setenv("MANALA_TEST_FOO", "env")
+	defer os.Unsetenv("MANALA_TEST_FOO")
+	prj, err = ld.Load(prjFile)
+	s.NoError(err)
+	s.Equal("env", prj.Vars()["foo"].(map[string]interface{})["foo"])
+}
+
+func (s *ProjectTestSuite) TestProjectLoadResolveUnresolved() {
+	ld := NewProjectLoader(s.repositoryLoader, s.recipeLoader, "", "", nil, nil, log.Log)
+	prjFile, err := ld.Find("testdata/project/load_resolve_unresolved", false)
+	s.NoError(err)
+	prj, err := ld.Load(prjFile)
+	s.Error(err)
+	s.Equal("invalid project config \"testdata/project/load_resolve_unresolved/.manala.yaml\" (unresolved secret \"bar\" at \"/foo/bar\" (line 5))", err.Error())
+	s.Nil(prj)
+}
@@ -3,6 +3,7 @@ package loaders
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	"github.com/apex/log"
 	"github.com/go-playground/validator/v10"
 	"github.com/imdario/mergo"
@@ -21,28 +22,52 @@ import (
 	"strings"
 )
 
-func NewRecipeLoader() RecipeLoaderInterface {
-	return &recipeLoader{}
+func NewRecipeLoader(version string, logger log.Interface) RecipeLoaderInterface {
+	return &recipeLoader{
+		version: version,
+		logger:  logger,
+	}
 }
 
 var recipeConfigFile = ".manala.yaml"
 
+// Repository shared helpers directory
+var repositoryHelpersDir = "_helpers"
+
+// Tell whether a repository file name could be the one of a recipe,
+// that is, neither a dot file, nor a path, nor the repository shared helpers directory
+func IsRecipeName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && name != repositoryHelpersDir && !strings.ContainsAny(name, `/\`)
+}
+
 type RecipeLoaderInterface interface {
 	Find(dir string) (*os.File, error)
 	Load(name string, repository models.RepositoryInterface) (models.RecipeInterface, error)
 	Walk(repository models.RepositoryInterface, fn recipeWalkFunc) error
+	LoadNode(name string, file string, node *yaml.Node, repository models.RepositoryInterface) (models.RecipeInterface, error)
+	CheckRequires(rec models.RecipeInterface) error
 }
 
 type recipeConfig struct {
-	Description string `validate:"required"`
-	Sync        []models.RecipeSyncUnit
+	Description string                  `validate:"required"`
+	Sync        []models.RecipeSyncUnit `validate:"dive"`
+	Env         []string
+	Normalize   models.RecipeNormalization
+	Tags        []string `validate:"dive,required"`
+	Maintainers []string `validate:"dive,required"`
+	Homepage    string   `validate:"omitempty,url"`
+	Deprecated  string
+	ReplacedBy  string `mapstructure:"replaced_by"`
+	Requires    string
 }
 
 type recipeLoader struct {
+	version string
+	logger  log.Interface
 }
 
 func (ld *recipeLoader) Find(dir string) (*os.File, error) {
-	log.WithField("dir", dir).Debug("Searching recipe...")
+	ld.logger.WithField("dir", dir).Debug("Searching recipe...")
 
 	file, err := os.Open(path.Join(dir, recipeConfigFile))
 
@@ -55,21 +80,53 @@ func (ld *recipeLoader) Find(dir string) (*os.File, error) {
 }
 
 func (ld *recipeLoader) Load(name string, repository models.RepositoryInterface) (models.RecipeInterface, error) {
-	var recipe models.RecipeInterface
+	// Exclude non recipe names, just like walk does
+	if !IsRecipeName(name) {
+		return nil, fmt.Errorf("recipe not found")
+	}
 
-	if err := ld.Walk(repository, func(rec models.RecipeInterface) {
-		if rec.Name() == name {
-			recipe = rec
-		}
-	}); err != nil {
+	// Only load named recipe, so that other recipes errors are not involved
+	recFile, err := ld.Find(filepath.Join(repository.Dir(), name))
+	if err != nil {
 		return nil, err
 	}
 
-	if recipe != nil {
-		return recipe, nil
+	if recFile == nil {
+		return nil, fmt.Errorf("recipe not found")
 	}
 
-	return nil, fmt.Errorf("recipe not found")
+	// Required manala version is not checked, so that incompatible recipes could still be
+	// shown or linted; it's up to the ones syncing them to call CheckRequires
+	return ld.loadDir(name, recFile, repository)
+}
+
+// Check recipe required manala version against the current one.
+// Development builds, as well as non semantic versions, are never checked.
+func (ld *recipeLoader) CheckRequires(rec models.RecipeInterface) error {
+	if rec.Requires() == "" {
+		return nil
+	}
+
+	version, err := semver.NewVersion(ld.version)
+	if err != nil {
+		return nil
+	}
+
+	// Pre-releases satisfy the constraints of their release
+	if release, err := version.SetPrerelease(""); err == nil {
+		version = &release
+	}
+
+	constraint, err := semver.NewConstraint(rec.Requires())
+	if err != nil {
+		return fmt.Errorf("invalid recipe requires \"%s\" (%w)", rec.Requires(), err)
+	}
+
+	if !constraint.Check(version) {
+		return fmt.Errorf("recipe \"%s\" requires manala %s, current version is %s, please upgrade manala", rec.Name(), rec.Requires(), ld.version)
+	}
+
+	return nil
 }
 
 func (ld *recipeLoader) Walk(repository models.RepositoryInterface, fn recipeWalkFunc) error {
@@ -79,8 +136,8 @@ func (ld *recipeLoader) Walk(repository models.RepositoryInterface, fn recipeWal
 	}
 
 	for _, file := range files {
-		// Exclude dot files
-		if strings.HasPrefix(file.Name(), ".") {
+		// Exclude dot files, and shared helpers
+		if !IsRecipeName(file.Name()) {
 			continue
 		}
 		if file.IsDir() {
@@ -102,10 +159,7 @@ func (ld *recipeLoader) Walk(repository models.RepositoryInterface, fn recipeWal
 type recipeWalkFunc func(rec models.RecipeInterface)
 
 func (ld *recipeLoader) loadDir(name string, file *os.File, repository models.RepositoryInterface) (models.RecipeInterface, error) {
-	// Get dir
-	dir := filepath.Dir(file.Name())
-
-	log.WithField("name", name).Debug("Loading recipe...")
+	ld.logger.WithField("name", name).Debug("Loading recipe...")
 
 	// Reset file pointer
 	_, err := file.Seek(0, io.SeekStart)
@@ -122,9 +176,17 @@ func (ld *recipeLoader) loadDir(name string, file *os.File, repository models.Re
 		return nil, fmt.Errorf("invalid recipe config \"%s\" (%w)", file.Name(), err)
 	}
 
+	return ld.LoadNode(name, file.Name(), &node, repository)
+}
+
+// Load a recipe from its already parsed config file node
+func (ld *recipeLoader) LoadNode(name string, file string, node *yaml.Node, repository models.RepositoryInterface) (models.RecipeInterface, error) {
+	// Get dir
+	dir := filepath.Dir(file)
+
 	var vars map[string]interface{}
 	if err := node.Decode(&vars); err != nil {
-		return nil, fmt.Errorf("incorrect recipe config \"%s\" (%w)", file.Name(), err)
+		return nil, fmt.Errorf("incorrect recipe config \"%s\" (%w)", file, err)
 	}
 
 	// See: https://github.com/go-yaml/yaml/issues/139
@@ -146,6 +208,20 @@ func (ld *recipeLoader) loadDir(name string, file *os.File, repository models.Re
 		return nil, err
 	}
 
+	// Ensure required manala version is a valid constraint
+	if cfg.Requires != "" {
+		if _, err := semver.NewConstraint(cfg.Requires); err != nil {
+			return nil, fmt.Errorf("invalid recipe requires \"%s\" (%w)", cfg.Requires, err)
+		}
+	}
+
+	// Sync units destinations default to their sources
+	for i := range cfg.Sync {
+		if cfg.Sync[i].Destination == "" {
+			cfg.Sync[i].Destination = cfg.Sync[i].Source
+		}
+	}
+
 	// Cleanup vars
 	delete(vars, "manala")
 
@@ -159,10 +235,20 @@ func (ld *recipeLoader) loadDir(name string, file *os.File, repository models.Re
 	// Handle config
 	rec.MergeVars(&vars)
 	rec.AddSyncUnits(cfg.Sync)
+	rec.AddEnv(cfg.Env)
+	rec.SetNormalization(cfg.Normalize)
+	rec.SetMetadata(models.RecipeMetadata{
+		Tags:        cfg.Tags,
+		Maintainers: cfg.Maintainers,
+		Homepage:    cfg.Homepage,
+		Deprecated:  cfg.Deprecated,
+		ReplacedBy:  cfg.ReplacedBy,
+	})
+	rec.SetRequires(cfg.Requires)
 
 	// Parse config node
 	var options []models.RecipeOption
-	schema, err := ld.parseConfigNode(&node, &options, "")
+	schema, err := ld.parseConfigNode(node, &options, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +258,7 @@ func (ld *recipeLoader) loadDir(name string, file *os.File, repository models.Re
 	return rec, nil
 }
 
-func (ld *recipeLoader) parseConfigNode(node *yaml.Node, options *[]models.RecipeOption, path string) (map[string]interface{}, error) {
+func (ld *recipeLoader) parseConfigNode(node *yaml.Node, options *[]models.RecipeOption, path string, infer bool) (map[string]interface{}, error) {
 	var nodeKey *yaml.Node = nil
 	schemaProperties := map[string]interface{}{}
 
@@ -187,15 +273,34 @@ func (ld *recipeLoader) parseConfigNode(node *yaml.Node, options *[]models.Recip
 				continue
 			}
 
+			var tags doc.TagList
+			if nodeKey.HeadComment != "" {
+				tags = doc.ParseCommentTags(nodeKey.HeadComment)
+			}
+
+			// Handle infer tags, applying on the whole node subtree
+			nodeInfer := infer
+			for _, tag := range tags.Filter("infer") {
+				var err error
+				if nodeInfer, err = strconv.ParseBool(tag.Value); err != nil {
+					return nil, fmt.Errorf("invalid recipe infer tag at \"%s\": %w", nodePath, err)
+				}
+			}
+
 			var schema map[string]interface{} = nil
 
 			switch nodeChild.Kind {
 			case yaml.ScalarNode:
 				// Both key/value node are scalars
 				schema = map[string]interface{}{}
+				if nodeInfer {
+					if t := inferScalarNodeType(nodeChild); t != "" {
+						schema["type"] = t
+					}
+				}
 			case yaml.MappingNode:
 				var err error
-				schema, err = ld.parseConfigNode(nodeChild, options, nodePath)
+				schema, err = ld.parseConfigNode(nodeChild, options, nodePath, nodeInfer)
 				if err != nil {
 					return nil, err
 				}
@@ -203,18 +308,34 @@ func (ld *recipeLoader) parseConfigNode(node *yaml.Node, options *[]models.Recip
 				schema = map[string]interface{}{
 					"type": "array",
 				}
+				if nodeInfer {
+					if items := inferSequenceNodeItems(nodeChild); items != nil {
+						schema["items"] = items
+					}
+				}
 			default:
 				return nil, fmt.Errorf("unknown node kind: %s", strconv.Itoa(int(nodeChild.Kind)))
 			}
 
 			if nodeKey.HeadComment != "" {
-				tags := doc.ParseCommentTags(nodeKey.HeadComment)
+				// Handle description
+				if description := doc.ParseCommentDescription(nodeKey.HeadComment); description != "" {
+					schema["description"] = description
+				}
 				// Handle schema tags
 				for _, tag := range tags.Filter("schema") {
 					var tagSchema map[string]interface{}
 					if err := json.Unmarshal([]byte(tag.Value), &tagSchema); err != nil {
 						return nil, fmt.Errorf("invalid recipe schema tag at \"%s\": %w", nodePath, err)
 					}
+					// Inferred type gives way to tag schema own type constraints
+					if nodeChild.Kind == yaml.ScalarNode {
+						for _, keyword := range []string{"enum", "const", "anyOf", "oneOf", "allOf", "$ref"} {
+							if _, ok := tagSchema[keyword]; ok {
+								delete(schema, "type")
+							}
+						}
+					}
 					if err := mergo.Merge(&schema, tagSchema, mergo.WithOverride); err != nil {
 						return nil, fmt.Errorf("unable to merge recipe schema tag at \"%s\": %w", nodePath, err)
 					}
@@ -247,7 +368,7 @@ func (ld *recipeLoader) parseConfigNode(node *yaml.Node, options *[]models.Recip
 				nodeKey = nodeChild
 			case yaml.MappingNode:
 				// This could only be the root node
-				schema, err := ld.parseConfigNode(nodeChild, options, "/")
+				schema, err := ld.parseConfigNode(nodeChild, options, "/", infer)
 				if err != nil {
 					return nil, err
 				}
@@ -276,6 +397,49 @@ func (ld *recipeLoader) parseConfigNode(node *yaml.Node, options *[]models.Recip
 	}, nil
 }
 
+// Infer scalar node schema type from its tag, null ones allowing any type
+func inferScalarNodeType(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case "!!str":
+		return "string"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	}
+	return ""
+}
+
+// Infer sequence node items schema, as long as they all share the same type
+func inferSequenceNodeItems(node *yaml.Node) map[string]interface{} {
+	itemsType := ""
+	for i, item := range node.Content {
+		t := ""
+		switch item.Kind {
+		case yaml.ScalarNode:
+			t = inferScalarNodeType(item)
+		case yaml.MappingNode:
+			t = "object"
+		case yaml.SequenceNode:
+			t = "array"
+		}
+		if t == "" || (i > 0 && t != itemsType) {
+			return nil
+		}
+		itemsType = t
+	}
+
+	if itemsType == "" {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"type": itemsType,
+	}
+}
+
 // Returns a DecodeHookFunc that converts strings to syncUnit
 func recipeStringToSyncUnitHookFunc() mapstructure.DecodeHookFunc {
 	return func(rf reflect.Type, rt reflect.Type, data interface{}) (interface{}, error) {
@@ -1,6 +1,7 @@
 package loaders
 
 import (
+	"github.com/apex/log"
 	"github.com/stretchr/testify/suite"
 	"manala/models"
 	"testing"
@@ -12,12 +13,17 @@ import (
 
 type RecipeTestSuite struct {
 	suite.Suite
-	repository              models.RepositoryInterface
-	repositoryEmpty         models.RepositoryInterface
-	repositoryInvalid       models.RepositoryInterface
-	repositoryIncorrect     models.RepositoryInterface
-	repositoryNoDescription models.RepositoryInterface
-	repositorySchemaInvalid models.RepositoryInterface
+	repository                 models.RepositoryInterface
+	repositoryEmpty            models.RepositoryInterface
+	repositoryInvalid          models.RepositoryInterface
+	repositoryIncorrect        models.RepositoryInterface
+	repositoryNoDescription    models.RepositoryInterface
+	repositorySchemaInvalid    models.RepositoryInterface
+	repositoryStrategyInvalid  models.RepositoryInterface
+	repositoryNormalizeInvalid models.RepositoryInterface
+	repositoryBroken           models.RepositoryInterface
+	repositoryMetadataInvalid  models.RepositoryInterface
+	repositoryRequiresInvalid  models.RepositoryInterface
 }
 
 func TestRecipeTestSuite(t *testing.T) {
@@ -32,6 +38,11 @@ func (s *RecipeTestSuite) SetupTest() {
 	s.repositoryIncorrect = models.NewRepository("testdata/recipe/_repository_incorrect", "testdata/recipe/_repository_incorrect")
 	s.repositoryNoDescription = models.NewRepository("testdata/recipe/_repository_no_description", "testdata/recipe/_repository_no_description")
 	s.repositorySchemaInvalid = models.NewRepository("testdata/recipe/_repository_schema_invalid", "testdata/recipe/_repository_schema_invalid")
+	s.repositoryStrategyInvalid = models.NewRepository("testdata/recipe/_repository_strategy_invalid", "testdata/recipe/_repository_strategy_invalid")
+	s.repositoryNormalizeInvalid = models.NewRepository("testdata/recipe/_repository_normalize_invalid", "testdata/recipe/_repository_normalize_invalid")
+	s.repositoryBroken = models.NewRepository("testdata/recipe/_repository_broken", "testdata/recipe/_repository_broken")
+	s.repositoryMetadataInvalid = models.NewRepository("testdata/recipe/_repository_metadata_invalid", "testdata/recipe/_repository_metadata_invalid")
+	s.repositoryRequiresInvalid = models.NewRepository("testdata/recipe/_repository_requires_invalid", "testdata/recipe/_repository_requires_invalid")
 }
 
 /******************/
@@ -39,7 +50,7 @@ func (s *RecipeTestSuite) SetupTest() {
 /******************/
 
 func (s *RecipeTestSuite) TestRecipe() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	s.Implements((*RecipeLoaderInterface)(nil), ld)
 }
 
@@ -60,7 +71,7 @@ func (s *RecipeTestSuite) TestRecipeFind() {
 		},
 	} {
 		s.Run(t.test, func() {
-			ld := NewRecipeLoader()
+			ld := NewRecipeLoader("", log.Log)
 			recFile, err := ld.Find(t.dir)
 			s.NoError(err)
 			if t.recFileName != "" {
@@ -74,7 +85,7 @@ func (s *RecipeTestSuite) TestRecipeFind() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoad() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load", s.repository)
 	s.NoError(err)
 	s.Implements((*models.RecipeInterface)(nil), rec)
@@ -85,15 +96,44 @@ func (s *RecipeTestSuite) TestRecipeLoad() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadNotFound() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("not_found", s.repository)
 	s.Error(err)
 	s.Equal("recipe not found", err.Error())
 	s.Nil(rec)
 }
 
+func (s *RecipeTestSuite) TestRecipeLoadExcluded() {
+	ld := NewRecipeLoader("", log.Log)
+	for _, name := range []string{"", "_helpers", ".git", "load/../load"} {
+		s.Run(name, func() {
+			rec, err := ld.Load(name, s.repository)
+			s.Error(err)
+			s.Equal("recipe not found", err.Error())
+			s.Nil(rec)
+		})
+	}
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadUnderscore() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("_load_underscore", s.repository)
+	s.NoError(err)
+	s.Equal("Load underscore", rec.Description())
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadBrokenRepository() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load", s.repositoryBroken)
+	s.NoError(err)
+	s.Equal("load", rec.Name())
+	rec, err = ld.Load("broken", s.repositoryBroken)
+	s.Error(err)
+	s.Nil(rec)
+}
+
 func (s *RecipeTestSuite) TestRecipeLoadEmpty() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load", s.repositoryEmpty)
 	s.Error(err)
 	s.Equal("empty recipe config \"testdata/recipe/_repository_empty/load/.manala.yaml\"", err.Error())
@@ -101,7 +141,7 @@ func (s *RecipeTestSuite) TestRecipeLoadEmpty() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadInvalid() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load", s.repositoryInvalid)
 	s.Error(err)
 	s.Equal("invalid recipe config \"testdata/recipe/_repository_invalid/load/.manala.yaml\" (yaml: mapping values are not allowed in this context)", err.Error())
@@ -109,7 +149,7 @@ func (s *RecipeTestSuite) TestRecipeLoadInvalid() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadIncorrect() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load", s.repositoryIncorrect)
 	s.Error(err)
 	s.Equal("incorrect recipe config \"testdata/recipe/_repository_incorrect/load/.manala.yaml\" (yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `foo` into map[string]interface {})", err.Error())
@@ -117,7 +157,7 @@ func (s *RecipeTestSuite) TestRecipeLoadIncorrect() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadNoDescription() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load", s.repositoryNoDescription)
 	s.Error(err)
 	s.Equal("Key: 'recipeConfig.Description' Error:Field validation for 'Description' failed on the 'required' tag", err.Error())
@@ -125,7 +165,7 @@ func (s *RecipeTestSuite) TestRecipeLoadNoDescription() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadVars() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load_vars", s.repository)
 	s.NoError(err)
 	s.Equal(
@@ -139,20 +179,125 @@ func (s *RecipeTestSuite) TestRecipeLoadVars() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadSyncUnits() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load_sync_units", s.repository)
 	s.NoError(err)
 	s.Equal(
 		[]models.RecipeSyncUnit{
 			{Source: "foo", Destination: "foo"},
 			{Source: "foo", Destination: "bar"},
+			{Source: "bar", Destination: "bar"},
+			{Source: "baz", Destination: "{{ .Item }}", Foreach: ".Vars.baz"},
+			{Source: "qux", Destination: "qux", Strategy: "block"},
+			{Source: "quux", Destination: "quux", Strategy: "merge"},
 		},
 		rec.SyncUnits(),
 	)
 }
 
+func (s *RecipeTestSuite) TestRecipeLoadSyncUnitsStrategyInvalid() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load", s.repositoryStrategyInvalid)
+	s.Error(err)
+	s.Equal("Key: 'recipeConfig.Sync[0].Strategy' Error:Field validation for 'Strategy' failed on the 'oneof' tag", err.Error())
+	s.Nil(rec)
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadEnv() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load_env", s.repository)
+	s.NoError(err)
+	s.Equal(
+		[]string{"FOO", "BAR"},
+		rec.Env(),
+	)
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadNormalize() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load_normalize", s.repository)
+	s.NoError(err)
+	s.Equal(
+		models.RecipeNormalization{Eol: "crlf", TrimTrailingWhitespace: true, FinalNewline: true},
+		rec.Normalization(),
+	)
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadNormalizeInvalid() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load", s.repositoryNormalizeInvalid)
+	s.Error(err)
+	s.Equal("Key: 'recipeConfig.Normalize.Eol' Error:Field validation for 'Eol' failed on the 'oneof' tag", err.Error())
+	s.Nil(rec)
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadMetadata() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load_metadata", s.repository)
+	s.NoError(err)
+	s.Equal(
+		models.RecipeMetadata{
+			Tags:        []string{"php", "docker"},
+			Maintainers: []string{"Foo <foo@example.com>"},
+			Homepage:    "https://example.com/load_metadata",
+			Deprecated:  "use load instead",
+			ReplacedBy:  "load",
+		},
+		rec.Metadata(),
+	)
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadMetadataInvalid() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load", s.repositoryMetadataInvalid)
+	s.Error(err)
+	s.Equal("Key: 'recipeConfig.Homepage' Error:Field validation for 'Homepage' failed on the 'url' tag", err.Error())
+	s.Nil(rec)
+}
+
+func (s *RecipeTestSuite) TestRecipeCheckRequires() {
+	for _, t := range []struct {
+		test    string
+		version string
+		err     string
+	}{
+		{test: "Compatible", version: "1.4.0"},
+		{test: "Compatible pre-release", version: "1.4.0-rc1"},
+		{test: "Development", version: "dev"},
+		{test: "Unknown", version: ""},
+		{
+			test:    "Incompatible",
+			version: "1.3.2",
+			err:     "recipe \"load_requires\" requires manala >=1.4, current version is 1.3.2, please upgrade manala",
+		},
+	} {
+		s.Run(t.test, func() {
+			ld := NewRecipeLoader(t.version, log.Log)
+			// Incompatible recipes are loaded anyway
+			rec, err := ld.Load("load_requires", s.repository)
+			s.NoError(err)
+			s.Equal(">=1.4", rec.Requires())
+			err = ld.CheckRequires(rec)
+			if t.err != "" {
+				s.Error(err)
+				s.Equal(t.err, err.Error())
+			} else {
+				s.NoError(err)
+			}
+		})
+	}
+}
+
+func (s *RecipeTestSuite) TestRecipeLoadRequiresInvalid() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load", s.repositoryRequiresInvalid)
+	s.Error(err)
+	s.Equal("invalid recipe requires \"foo\" (improper constraint: foo)", err.Error())
+	s.Nil(rec)
+}
+
 func (s *RecipeTestSuite) TestRecipeLoadSchema() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load_schema", s.repository)
 	s.NoError(err)
 	s.Equal(
@@ -163,8 +308,9 @@ func (s *RecipeTestSuite) TestRecipeLoadSchema() {
 				"foo": map[string]interface{}{
 					"type":                 "object",
 					"additionalProperties": false,
+					"description":          "Foo",
 					"properties": map[string]interface{}{
-						"foo": map[string]interface{}{},
+						"foo": map[string]interface{}{"type": "string"},
 						"bar": map[string]interface{}{
 							"enum": []interface{}{
 								nil,
@@ -183,8 +329,9 @@ func (s *RecipeTestSuite) TestRecipeLoadSchema() {
 				"bar": map[string]interface{}{
 					"type":                 "object",
 					"additionalProperties": false,
+					"description":          "Bar\ndescription",
 					"properties": map[string]interface{}{
-						"bar": map[string]interface{}{},
+						"bar": map[string]interface{}{"type": "string"},
 					},
 				},
 				"additionalProperties": map[string]interface{}{
@@ -195,16 +342,16 @@ func (s *RecipeTestSuite) TestRecipeLoadSchema() {
 							"type":                 "object",
 							"additionalProperties": false,
 							"properties": map[string]interface{}{
-								"foo": map[string]interface{}{},
-								"bar": map[string]interface{}{},
+								"foo": map[string]interface{}{"type": "string"},
+								"bar": map[string]interface{}{"type": "string"},
 							},
 						},
 						"object_overriden": map[string]interface{}{
 							"type":                 "object",
 							"additionalProperties": true,
 							"properties": map[string]interface{}{
-								"foo": map[string]interface{}{},
-								"bar": map[string]interface{}{},
+								"foo": map[string]interface{}{"type": "string"},
+								"bar": map[string]interface{}{"type": "string"},
 							},
 						},
 						"empty_object": map[string]interface{}{
@@ -225,8 +372,51 @@ func (s *RecipeTestSuite) TestRecipeLoadSchema() {
 	)
 }
 
+func (s *RecipeTestSuite) TestRecipeLoadSchemaInfer() {
+	ld := NewRecipeLoader("", log.Log)
+	rec, err := ld.Load("load_schema_infer", s.repository)
+	s.NoError(err)
+	s.Equal(
+		map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"string":          map[string]interface{}{"type": "string"},
+				"string_quoted":   map[string]interface{}{"type": "string"},
+				"integer":         map[string]interface{}{"type": "integer"},
+				"number":          map[string]interface{}{"type": "number"},
+				"boolean":         map[string]interface{}{"type": "boolean"},
+				"null":            map[string]interface{}{},
+				"array":           map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				"array_objects":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+				"array_mixed":     map[string]interface{}{"type": "array"},
+				"array_empty":     map[string]interface{}{"type": "array"},
+				"enum":            map[string]interface{}{"enum": []interface{}{nil, "foo"}},
+				"type_overridden": map[string]interface{}{"type": []interface{}{"string", "integer"}},
+				"not_inferred": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]interface{}{
+						"string": map[string]interface{}{},
+						"array":  map[string]interface{}{"type": "array"},
+						"inferred": map[string]interface{}{
+							"type":                 "object",
+							"additionalProperties": false,
+							"properties": map[string]interface{}{
+								"integer": map[string]interface{}{"type": "integer"},
+							},
+						},
+					},
+				},
+				"after_not_inferred": map[string]interface{}{"type": "string"},
+			},
+		},
+		rec.Schema(),
+	)
+}
+
 func (s *RecipeTestSuite) TestRecipeLoadSchemaInvalid() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load", s.repositorySchemaInvalid)
 	s.Error(err)
 	s.Equal("invalid recipe schema tag at \"/foo\": unexpected end of JSON input", err.Error())
@@ -234,7 +424,7 @@ func (s *RecipeTestSuite) TestRecipeLoadSchemaInvalid() {
 }
 
 func (s *RecipeTestSuite) TestRecipeLoadOptions() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	rec, err := ld.Load("load_options", s.repository)
 	s.NoError(err)
 	s.Equal(
@@ -246,16 +436,35 @@ func (s *RecipeTestSuite) TestRecipeLoadOptions() {
 }
 
 func (s *RecipeTestSuite) TestRecipeWalk() {
-	ld := NewRecipeLoader()
+	ld := NewRecipeLoader("", log.Log)
 	results := make(map[string]string)
 	err := ld.Walk(s.repository, func(rec models.RecipeInterface) {
 		results[rec.Name()] = rec.Description()
 	})
 	s.NoError(err)
-	s.Len(results, 5)
+	s.Len(results, 11)
 	s.Equal("Load", results["load"])
+	s.Equal("Load underscore", results["_load_underscore"])
 	s.Equal("Load vars", results["load_vars"])
 	s.Equal("Load sync units", results["load_sync_units"])
 	s.Equal("Load schema", results["load_schema"])
+	s.Equal("Load schema infer", results["load_schema_infer"])
 	s.Equal("Load options", results["load_options"])
+	s.Equal("Load env", results["load_env"])
+	s.Equal("Load normalize", results["load_normalize"])
+	s.Equal("Load metadata", results["load_metadata"])
+	s.Equal("Load requires", results["load_requires"])
+}
+
+func (s *RecipeTestSuite) TestRecipeWalkIncompatible() {
+	ld := NewRecipeLoader("1.0.0", log.Log)
+	var rec models.RecipeInterface
+	err := ld.Walk(s.repository, func(r models.RecipeInterface) {
+		if r.Name() == "load_requires" {
+			rec = r
+		}
+	})
+	s.NoError(err)
+	s.NotNil(rec)
+	s.Error(ld.CheckRequires(rec))
 }
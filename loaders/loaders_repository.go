@@ -12,11 +12,12 @@ import (
 	"path"
 )
 
-func NewRepositoryLoader(cacheDir string, defaultSrc string) RepositoryLoaderInterface {
+func NewRepositoryLoader(cacheDir string, defaultSrc string, logger log.Interface) RepositoryLoaderInterface {
 	return &repositoryLoader{
 		cacheDir:   cacheDir,
 		cache:      make(map[string]models.RepositoryInterface),
 		defaultSrc: defaultSrc,
+		logger:     logger,
 	}
 }
 
@@ -28,6 +29,7 @@ type repositoryLoader struct {
 	cacheDir   string
 	cache      map[string]models.RepositoryInterface
 	defaultSrc string
+	logger     log.Interface
 }
 
 func (ld *repositoryLoader) Load(src string) (models.RepositoryInterface, error) {
@@ -62,7 +64,7 @@ func (ld *repositoryLoader) Load(src string) (models.RepositoryInterface, error)
 }
 
 func (ld *repositoryLoader) loadDir(src string) (models.RepositoryInterface, error) {
-	log.WithField("src", src).Debug("Loading dir repository...")
+	ld.logger.WithField("src", src).Debug("Loading dir repository...")
 
 	stat, err := os.Stat(src)
 	if err != nil {
@@ -81,12 +83,12 @@ func (ld *repositoryLoader) loadGit(src string) (models.RepositoryInterface, err
 	hash := md5.New()
 	hash.Write([]byte(src))
 
-	log.WithField("src", src).Debug("Loading git repository...")
+	ld.logger.WithField("src", src).Debug("Loading git repository...")
 
 	// Repository cache directory should be unique
 	dir := path.Join(ld.cacheDir, "repositories", hex.EncodeToString(hash.Sum(nil)))
 
-	log.WithField("dir", dir).Debug("Opening repository cache...")
+	ld.logger.WithField("dir", dir).Debug("Opening repository cache...")
 
 Load:
 	if err := os.MkdirAll(dir, os.FileMode(0700)); err != nil {
@@ -99,7 +101,7 @@ Load:
 
 	// Repository not in cache, let's clone it
 	case git.ErrRepositoryNotExists:
-		log.Debug("Cloning git repository cache...")
+		ld.logger.Debug("Cloning git repository cache...")
 
 		gitRepository, err = git.PlainClone(dir, false, &git.CloneOptions{
 			URL:               src,
@@ -111,14 +113,14 @@ Load:
 
 	// Repository already in cache, let's pull it
 	case nil:
-		log.Debug("Getting git repository worktree cache...")
+		ld.logger.Debug("Getting git repository worktree cache...")
 
 		gitRepositoryWorktree, err := gitRepository.Worktree()
 		if err != nil {
 			return nil, fmt.Errorf("invalid repository: %w", err)
 		}
 
-		log.Debug("Pulling cache git repository worktree...")
+		ld.logger.Debug("Pulling cache git repository worktree...")
 
 		if err := gitRepositoryWorktree.Pull(&git.PullOptions{
 			RemoteName: "origin",
@@ -126,7 +128,7 @@ Load:
 			switch err {
 			case git.NoErrAlreadyUpToDate:
 			case git.ErrNonFastForwardUpdate:
-				log.Debug("Fast forward update detected, delete repository cache and retry with cloning...")
+				ld.logger.Debug("Fast forward update detected, delete repository cache and retry with cloning...")
 				if err := os.RemoveAll(dir); err != nil {
 					return nil, fmt.Errorf("unable to delete repository cache: %w", err)
 				}
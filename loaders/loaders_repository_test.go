@@ -1,6 +1,7 @@
 package loaders
 
 import (
+	"github.com/apex/log"
 	"github.com/stretchr/testify/suite"
 	"manala/models"
 	"os"
@@ -32,12 +33,12 @@ func (s *RepositoryTestSuite) SetupTest() {
 /**********************/
 
 func (s *RepositoryTestSuite) TestRepository() {
-	ld := NewRepositoryLoader(s.cacheDir, "")
+	ld := NewRepositoryLoader(s.cacheDir, "", log.Log)
 	s.Implements((*RepositoryLoaderInterface)(nil), ld)
 }
 
 func (s *RepositoryTestSuite) TestRepositoryLoadDir() {
-	ld := NewRepositoryLoader(s.cacheDir, "")
+	ld := NewRepositoryLoader(s.cacheDir, "", log.Log)
 	repo, err := ld.Load("testdata/repository/load_dir")
 	s.NoError(err)
 	s.Implements((*models.RepositoryInterface)(nil), repo)
@@ -46,7 +47,7 @@ func (s *RepositoryTestSuite) TestRepositoryLoadDir() {
 }
 
 func (s *RepositoryTestSuite) TestRepositoryDefaultLoadDir() {
-	ld := NewRepositoryLoader(s.cacheDir, "testdata/repository/load_dir")
+	ld := NewRepositoryLoader(s.cacheDir, "testdata/repository/load_dir", log.Log)
 	repo, err := ld.Load("")
 	s.NoError(err)
 	s.Implements((*models.RepositoryInterface)(nil), repo)
@@ -55,7 +56,7 @@ func (s *RepositoryTestSuite) TestRepositoryDefaultLoadDir() {
 }
 
 func (s *RepositoryTestSuite) TestRepositoryLoadDirNotFound() {
-	ld := NewRepositoryLoader(s.cacheDir, "")
+	ld := NewRepositoryLoader(s.cacheDir, "", log.Log)
 	repo, err := ld.Load("testdata/repository/load_dir_not_found")
 	s.Error(err)
 	s.Equal("\"testdata/repository/load_dir_not_found\" directory does not exists", err.Error())
@@ -63,7 +64,7 @@ func (s *RepositoryTestSuite) TestRepositoryLoadDirNotFound() {
 }
 
 func (s *RepositoryTestSuite) TestRepositoryLoadDirFile() {
-	ld := NewRepositoryLoader(s.cacheDir, "")
+	ld := NewRepositoryLoader(s.cacheDir, "", log.Log)
 	repo, err := ld.Load("testdata/repository/load_dir_file")
 	s.Error(err)
 	s.Equal("\"testdata/repository/load_dir_file\" is not a directory", err.Error())
@@ -71,7 +72,7 @@ func (s *RepositoryTestSuite) TestRepositoryLoadDirFile() {
 }
 
 func (s *RepositoryTestSuite) TestRepositoryLoadGit() {
-	ld := NewRepositoryLoader(s.cacheDir, "")
+	ld := NewRepositoryLoader(s.cacheDir, "", log.Log)
 	repo, err := ld.Load("https://github.com/octocat/Hello-World.git")
 	s.NoError(err)
 	s.Implements((*models.RepositoryInterface)(nil), repo)
@@ -88,7 +89,7 @@ func (s *RepositoryTestSuite) TestRepositoryLoadGit() {
 }
 
 func (s *RepositoryTestSuite) TestRepositoryLoadGitNotExist() {
-	ld := NewRepositoryLoader(s.cacheDir, "")
+	ld := NewRepositoryLoader(s.cacheDir, "", log.Log)
 	repo, err := ld.Load("https://github.com/octocat/Foo-Bar.git")
 	s.Error(err)
 	s.Equal("unable to clone repository: authentication required", err.Error())
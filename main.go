@@ -6,12 +6,10 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"manala/cmd"
+	"manala/manala"
 	"os"
 )
 
-// Default repository
-var repository = "https://github.com/manala/manala-recipes.git"
-
 // Set at build time, by goreleaser, via ldflags
 var version = "dev"
 
@@ -23,7 +21,7 @@ func main() {
 	viper.SetEnvPrefix("manala")
 	viper.AutomaticEnv()
 
-	viper.SetDefault("repository", repository)
+	viper.SetDefault("repository", manala.DefaultRepository)
 	viper.SetDefault("debug", false)
 
 	cacheDir, err := os.UserCacheDir()
@@ -36,7 +34,11 @@ func main() {
 	rootCmd := cmd.RootCmd(version)
 	rootCmd.AddCommand(cmd.InitCmd())
 	rootCmd.AddCommand(cmd.ListCmd())
+	rootCmd.AddCommand(cmd.RecipeCmd())
+	rootCmd.AddCommand(cmd.SchemaCmd())
+	rootCmd.AddCommand(cmd.ShowCmd())
 	rootCmd.AddCommand(cmd.UpdateCmd())
+	rootCmd.AddCommand(cmd.ValidateCmd())
 	rootCmd.AddCommand(cmd.WatchCmd())
 
 	// Documentation
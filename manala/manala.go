@@ -0,0 +1,463 @@
+package manala
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"manala/loaders"
+	"manala/models"
+	"manala/syncer"
+	"manala/validator"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Default repository
+const DefaultRepository = "https://github.com/manala/manala-recipes.git"
+
+// Client options
+type Options struct {
+	// Repositories cache directory, used by git repositories
+	CacheDir string
+	// Default repository source
+	Repository string
+	// Manala version, recipes requirements are checked against
+	Version string
+	// Logger, default to none
+	Logger log.Interface
+}
+
+// Create a client, driving manala the same way its commands do
+func NewClient(options Options) *Client {
+	if options.Logger == nil {
+		options.Logger = &log.Logger{
+			Handler: discard.New(),
+			Level:   log.InfoLevel,
+		}
+	}
+
+	return &Client{
+		options: options,
+	}
+}
+
+type Client struct {
+	options Options
+}
+
+/********/
+/* List */
+/********/
+
+// List options
+type ListOptions struct {
+	// Repository source, default to client one
+	Repository string
+	// Only list recipes having all these tags
+	Tags []string
+}
+
+// Listed recipe details
+type Recipe struct {
+	Name         string       `json:"name" yaml:"name"`
+	Description  string       `json:"description" yaml:"description"`
+	Repository   string       `json:"repository" yaml:"repository"`
+	Options      int          `json:"options" yaml:"options"`
+	Sync         []RecipeSync `json:"sync" yaml:"sync"`
+	Tags         []string     `json:"tags" yaml:"tags"`
+	Deprecated   string       `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	ReplacedBy   string       `json:"replaced_by,omitempty" yaml:"replaced_by,omitempty"`
+	Requires     string       `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Incompatible bool         `json:"incompatible,omitempty" yaml:"incompatible,omitempty"`
+}
+
+type RecipeSync struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	Foreach     string `json:"foreach,omitempty" yaml:"foreach,omitempty"`
+	Strategy    string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+}
+
+// List repository recipes
+func (c *Client) List(options ListOptions) ([]Recipe, error) {
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader(c.options.CacheDir, c.options.Repository, c.options.Logger)
+	recLoader := loaders.NewRecipeLoader(c.options.Version, c.options.Logger)
+
+	// Load repository
+	repo, err := repoLoader.Load(options.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk into recipes
+	recipes := []Recipe{}
+	if err := recLoader.Walk(repo, func(rec models.RecipeInterface) {
+		// Filter by tags, all of them
+		for _, tag := range options.Tags {
+			if !rec.Metadata().HasTag(tag) {
+				return
+			}
+		}
+
+		recipe := Recipe{
+			Name:         rec.Name(),
+			Description:  rec.Description(),
+			Repository:   rec.Repository().Src(),
+			Options:      len(rec.Options()),
+			Sync:         []RecipeSync{},
+			Tags:         []string{},
+			Deprecated:   rec.Metadata().Deprecated,
+			ReplacedBy:   rec.Metadata().ReplacedBy,
+			Requires:     rec.Requires(),
+			Incompatible: recLoader.CheckRequires(rec) != nil,
+		}
+		recipe.Tags = append(recipe.Tags, rec.Metadata().Tags...)
+		for _, unit := range rec.SyncUnits() {
+			recipe.Sync = append(recipe.Sync, RecipeSync{
+				Source:      unit.Source,
+				Destination: unit.Destination,
+				Foreach:     unit.Foreach,
+				Strategy:    unit.Strategy,
+			})
+		}
+		recipes = append(recipes, recipe)
+	}); err != nil {
+		return nil, err
+	}
+
+	return recipes, nil
+}
+
+/********/
+/* Init */
+/********/
+
+// Init options
+type InitOptions struct {
+	// Project directory, default to current one, created if needed
+	Dir string
+	// Repository source, default to client one
+	Repository string
+	// Recipe name
+	Recipe string
+	// Vars, merged into recipe ones
+	Vars map[string]interface{}
+	// Report changes, without making them
+	DryRun bool
+}
+
+// Synced project details
+type Project struct {
+	Dir        string          `json:"dir" yaml:"dir"`
+	Recipe     string          `json:"recipe" yaml:"recipe"`
+	Repository string          `json:"repository" yaml:"repository"`
+	Deprecated string          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Changes    []syncer.Change `json:"changes" yaml:"changes"`
+}
+
+// Init a project, without any user interaction
+func (c *Client) Init(options InitOptions) (*Project, error) {
+	if options.Recipe == "" {
+		return nil, fmt.Errorf("recipe required")
+	}
+
+	// Loaders
+	repoLoader := loaders.NewRepositoryLoader(c.options.CacheDir, c.options.Repository, c.options.Logger)
+	recLoader := loaders.NewRecipeLoader(c.options.Version, c.options.Logger)
+	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, "", "", nil, nil, c.options.Logger)
+
+	// Directory
+	dir := options.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	// Ensure directory exists
+	stat, err := os.Stat(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error getting project directory stat: %v", err)
+		}
+		if !options.DryRun {
+			c.options.Logger.WithField("dir", dir).Debug("Creating project directory...")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("error creating project directory: %v", err)
+			}
+			c.options.Logger.WithField("dir", dir).Info("Project directory created")
+		}
+	} else if !stat.IsDir() {
+		return nil, fmt.Errorf("project directory invalid: %s", dir)
+	}
+
+	// Ensure no project already exists
+	if prjFile, _ := prjLoader.Find(dir, false); prjFile != nil {
+		return nil, fmt.Errorf("project already exists: %s", dir)
+	}
+
+	// Load repository
+	repo, err := repoLoader.Load(options.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load recipe
+	rec, err := recLoader.Load(options.Recipe, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recLoader.CheckRequires(rec); err != nil {
+		return nil, err
+	}
+
+	// Project
+	prj := models.NewProject(dir, rec)
+	if options.Vars != nil {
+		vars := options.Vars
+		prj.MergeVars(&vars)
+	}
+
+	return c.sync(prj, options.DryRun)
+}
+
+/**********/
+/* Update */
+/**********/
+
+// Update options
+type UpdateOptions struct {
+	// Project directory, default to current one
+	Dir string
+	// Force repository source
+	Repository string
+	// Force recipe name
+	Recipe string
+	// Update all projects found in directory, recursively
+	Recursive bool
+	// Values files, merged in order
+	ValuesFiles []string
+	// Set values (key.path=value)
+	SetValues []string
+	// Report changes, without making them
+	DryRun bool
+}
+
+// Update projects
+func (c *Client) Update(options UpdateOptions) ([]Project, error) {
+	prjLoader := c.projectLoader(options.Repository, options.Recipe, options.ValuesFiles, options.SetValues)
+
+	projects := []Project{}
+	if err := walkProjects(prjLoader, options.Dir, options.Recursive, func(prjFile *os.File) error {
+		// Load project
+		prj, err := prjLoader.Load(prjFile)
+		if err != nil {
+			return err
+		}
+
+		project, err := c.sync(prj, options.DryRun)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, *project)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// Validate and sync a project
+func (c *Client) sync(prj models.ProjectInterface, dryRun bool) (*Project, error) {
+	project := &Project{
+		Dir:        prj.Dir(),
+		Recipe:     prj.Recipe().Name(),
+		Repository: prj.Recipe().Repository().Src(),
+		Deprecated: prj.Recipe().Metadata().Deprecated,
+	}
+
+	// Warn about deprecated recipe
+	if metadata := prj.Recipe().Metadata(); metadata.Deprecated != "" {
+		fields := log.Fields{"recipe": prj.Recipe().Name()}
+		if metadata.ReplacedBy != "" {
+			fields["replaced_by"] = metadata.ReplacedBy
+		}
+		c.options.Logger.WithFields(fields).Warn("Recipe deprecated: " + metadata.Deprecated)
+	}
+
+	// Validate project
+	if err := validator.ValidateProject(prj); err != nil {
+		return nil, err
+	}
+
+	c.options.Logger.Info("Project validated")
+
+	// Sync project
+	changes, err := syncer.SyncProjectOptions(prj, c.options.Version, syncer.Options{
+		DryRun: dryRun,
+		Logger: c.options.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	project.Changes = changes
+
+	if !dryRun {
+		c.options.Logger.Info("Project synced")
+	}
+
+	return project, nil
+}
+
+/************/
+/* Validate */
+/************/
+
+// Validate options
+type ValidateOptions struct {
+	// Project directory, default to current one
+	Dir string
+	// Force repository source
+	Repository string
+	// Force recipe name
+	Recipe string
+	// Validate all projects found in directory, recursively
+	Recursive bool
+	// Values files, merged in order
+	ValuesFiles []string
+	// Set values (key.path=value)
+	SetValues []string
+}
+
+// Project violation, located in the project config file defining its value, if any
+type Violation = validator.ProjectViolation
+
+// Validate projects, and get their violations, sorted by location
+func (c *Client) Validate(options ValidateOptions) ([]Violation, error) {
+	prjLoader := c.projectLoader(options.Repository, options.Recipe, options.ValuesFiles, options.SetValues)
+
+	violations := []Violation{}
+	if err := walkProjects(prjLoader, options.Dir, options.Recursive, func(prjFile *os.File) error {
+		prjViolations, err := c.validateProject(prjLoader, prjFile)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, prjViolations...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return violations, nil
+}
+
+func (c *Client) validateProject(prjLoader loaders.ProjectLoaderInterface, prjFile *os.File) ([]Violation, error) {
+	// Load project
+	prj, err := prjLoader.Load(prjFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate project
+	err = validator.ValidateProject(prj)
+	if err == nil {
+		c.options.Logger.Info("Project validated")
+		return nil, nil
+	}
+
+	validationErr, ok := err.(*validator.ProjectValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	// Get project config layers, to locate violations
+	layers, err := prjLoader.Layers(prjFile)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validationErr.Violations(layers...)
+
+	// Sort by location
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Line != violations[j].Line {
+			return violations[i].Line < violations[j].Line
+		}
+		if violations[i].Column != violations[j].Column {
+			return violations[i].Column < violations[j].Column
+		}
+		return violations[i].Pointer < violations[j].Pointer
+	})
+
+	return violations, nil
+}
+
+/***********/
+/* Helpers */
+/***********/
+
+func (c *Client) projectLoader(repository string, recipe string, valuesFiles []string, setValues []string) loaders.ProjectLoaderInterface {
+	repoLoader := loaders.NewRepositoryLoader(c.options.CacheDir, c.options.Repository, c.options.Logger)
+	recLoader := loaders.NewRecipeLoader(c.options.Version, c.options.Logger)
+
+	return loaders.NewProjectLoader(repoLoader, recLoader, repository, recipe, valuesFiles, setValues, c.options.Logger)
+}
+
+// Walk into directory projects, either recursively, or traversing up to the first found one
+func walkProjects(prjLoader loaders.ProjectLoaderInterface, dir string, recursive bool, fn func(prjFile *os.File) error) error {
+	if dir == "" {
+		dir = "."
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("invalid directory: %s", dir)
+	}
+
+	if !recursive {
+		// Find project file
+		prjFile, err := prjLoader.Find(dir, true)
+		if err != nil {
+			return err
+		}
+
+		if prjFile == nil {
+			return fmt.Errorf("project not found: %s", dir)
+		}
+
+		return fn(prjFile)
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		// Only directories
+		if err != nil || !info.IsDir() {
+			return err
+		}
+
+		// Only not dotted directories
+		// (except - of course - current directory)
+		if strings.HasPrefix(filepath.Base(path), ".") && (path != ".") {
+			return filepath.SkipDir
+		}
+
+		// Find project file
+		prjFile, err := prjLoader.Find(path, false)
+		if err != nil {
+			return err
+		}
+
+		if prjFile != nil {
+			return fn(prjFile)
+		}
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package manala
+
+import (
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"manala/syncer"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/******************/
+/* Client - Suite */
+/******************/
+
+type ClientTestSuite struct {
+	suite.Suite
+	dir    string
+	client *Client
+}
+
+func TestClientTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(ClientTestSuite))
+}
+
+func (s *ClientTestSuite) SetupTest() {
+	s.dir, _ = ioutil.TempDir("", "manala-client")
+	s.client = NewClient(Options{
+		Repository: "testdata/repository",
+		Version:    "1.0.0",
+	})
+}
+
+func (s *ClientTestSuite) TearDownTest() {
+	_ = os.RemoveAll(s.dir)
+}
+
+// Create a project, in its own directory
+func (s *ClientTestSuite) project(name string, config string) string {
+	dir := filepath.Join(s.dir, name)
+	_ = os.MkdirAll(dir, 0755)
+	_ = ioutil.WriteFile(filepath.Join(dir, ".manala.yaml"), []byte(config), 0666)
+
+	return dir
+}
+
+/******************/
+/* Client - Tests */
+/******************/
+
+func (s *ClientTestSuite) TestList() {
+	recipes, err := s.client.List(ListOptions{})
+	s.NoError(err)
+	s.Equal([]Recipe{
+		{
+			Name:        "bar",
+			Description: "Bar recipe",
+			Repository:  "testdata/repository",
+			Sync:        []RecipeSync{{Source: "file", Destination: "file"}},
+			Tags:        []string{"bar"},
+			Deprecated:  "Use foo instead",
+			ReplacedBy:  "foo",
+		},
+		{
+			Name:        "foo",
+			Description: "Foo recipe",
+			Repository:  "testdata/repository",
+			Sync:        []RecipeSync{{Source: "file.tmpl", Destination: "file"}},
+			Tags:        []string{"foo"},
+		},
+	}, recipes)
+
+	recipes, err = s.client.List(ListOptions{Tags: []string{"foo"}})
+	s.NoError(err)
+	s.Len(recipes, 1)
+	s.Equal("foo", recipes[0].Name)
+
+	_, err = s.client.List(ListOptions{Repository: "testdata/not_found"})
+	s.Error(err)
+}
+
+func (s *ClientTestSuite) TestInit() {
+	dir := filepath.Join(s.dir, "project")
+
+	// Dry run
+	project, err := s.client.Init(InitOptions{
+		Dir:    dir,
+		Recipe: "foo",
+		Vars:   map[string]interface{}{"foo": "bar"},
+		DryRun: true,
+	})
+	s.NoError(err)
+	s.Equal(&Project{
+		Dir:        dir,
+		Recipe:     "foo",
+		Repository: "testdata/repository",
+		Changes: []syncer.Change{
+			{Path: filepath.Join(dir, "file"), Action: "created"},
+		},
+	}, project)
+	s.NoDirExists(dir)
+
+	project, err = s.client.Init(InitOptions{
+		Dir:    dir,
+		Recipe: "foo",
+		Vars:   map[string]interface{}{"foo": "bar"},
+	})
+	s.NoError(err)
+	s.Len(project.Changes, 1)
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "file"))
+	s.Equal("foo: bar\n", string(content))
+}
+
+func (s *ClientTestSuite) TestInitErrors() {
+	for _, t := range []struct {
+		test    string
+		options InitOptions
+		err     string
+	}{
+		{
+			test:    "Recipe required",
+			options: InitOptions{},
+			err:     "recipe required",
+		},
+		{
+			test:    "Recipe not found",
+			options: InitOptions{Recipe: "baz"},
+			err:     "recipe not found",
+		},
+		{
+			test:    "Invalid vars",
+			options: InitOptions{Recipe: "foo", Vars: map[string]interface{}{"foo": "baz"}},
+			err:     "project config errors",
+		},
+	} {
+		s.Run(t.test, func() {
+			t.options.Dir = filepath.Join(s.dir, "project")
+			_, err := s.client.Init(t.options)
+			s.Error(err)
+			s.Contains(err.Error(), t.err)
+			s.NoFileExists(filepath.Join(s.dir, "project", "file"))
+		})
+	}
+
+	dir := s.project("existing", "manala:\n  recipe: foo\n")
+	_, err := s.client.Init(InitOptions{Dir: dir, Recipe: "foo"})
+	s.Error(err)
+	s.Equal("project already exists: "+dir, err.Error())
+}
+
+func (s *ClientTestSuite) TestUpdate() {
+	dir := s.project("project", "manala:\n  recipe: foo\n\nfoo: bar\n")
+
+	// Dry run
+	projects, err := s.client.Update(UpdateOptions{Dir: dir, DryRun: true})
+	s.NoError(err)
+	s.Equal([]Project{
+		{
+			Dir:        dir,
+			Recipe:     "foo",
+			Repository: "testdata/repository",
+			Changes: []syncer.Change{
+				{Path: filepath.Join(dir, "file"), Action: "created"},
+			},
+		},
+	}, projects)
+	s.NoFileExists(filepath.Join(dir, "file"))
+
+	projects, err = s.client.Update(UpdateOptions{Dir: dir, SetValues: []string{"foo=foo"}})
+	s.NoError(err)
+	s.Len(projects[0].Changes, 1)
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "file"))
+	s.Equal("foo: foo\n", string(content))
+
+	// Nothing left to change
+	projects, err = s.client.Update(UpdateOptions{Dir: dir, SetValues: []string{"foo=foo"}})
+	s.NoError(err)
+	s.Empty(projects[0].Changes)
+}
+
+func (s *ClientTestSuite) TestUpdateRecursive() {
+	s.project("foo", "manala:\n  recipe: foo\n")
+	s.project("bar", "manala:\n  recipe: bar\n")
+	s.project(".baz", "manala:\n  recipe: foo\n")
+
+	projects, err := s.client.Update(UpdateOptions{Dir: s.dir, Recursive: true})
+	s.NoError(err)
+	s.Len(projects, 2)
+	s.Equal("bar", projects[0].Recipe)
+	s.Equal("Use foo instead", projects[0].Deprecated)
+	s.Equal("foo", projects[1].Recipe)
+	s.NoFileExists(filepath.Join(s.dir, ".baz", "file"))
+}
+
+func (s *ClientTestSuite) TestUpdateErrors() {
+	_, err := s.client.Update(UpdateOptions{Dir: filepath.Join(s.dir, "not_found")})
+	s.Error(err)
+	s.Equal("invalid directory: "+filepath.Join(s.dir, "not_found"), err.Error())
+
+	_, err = s.client.Update(UpdateOptions{Dir: s.dir})
+	s.Error(err)
+	s.Equal("project not found: "+s.dir, err.Error())
+}
+
+func (s *ClientTestSuite) TestValidate() {
+	dir := s.project("project", "manala:\n  recipe: foo\n\nfoo: baz\nbar: baz\n")
+
+	violations, err := s.client.Validate(ValidateOptions{Dir: dir})
+	s.NoError(err)
+	s.Len(violations, 2)
+	s.Equal(filepath.Join(dir, ".manala.yaml"), violations[0].File)
+	s.Equal("/foo", violations[0].Pointer)
+	s.Equal("enum", violations[0].Keyword)
+	s.Equal(4, violations[0].Line)
+	s.Equal("/bar", violations[1].Pointer)
+	s.Equal("additionalProperties", violations[1].Keyword)
+
+	violations, err = s.client.Validate(ValidateOptions{Dir: dir, SetValues: []string{"foo=bar"}})
+	s.NoError(err)
+	s.Len(violations, 1)
+}
+
+func (s *ClientTestSuite) TestLogger() {
+	handler := memory.New()
+	logger := log.Log
+
+	client := NewClient(Options{
+		Repository: "testdata/repository",
+		Logger:     &log.Logger{Handler: handler, Level: log.InfoLevel},
+	})
+
+	dir := s.project("project", "manala:\n  recipe: foo\n")
+	_, err := client.Update(UpdateOptions{Dir: dir})
+	s.NoError(err)
+
+	var messages []string
+	for _, entry := range handler.Entries {
+		messages = append(messages, entry.Message)
+	}
+	s.Equal([]string{"Project loaded", "Repository loaded", "Recipe loaded", "Project validated", "Synced file", "Project synced"}, messages)
+
+	// Global logger is left untouched
+	s.Equal(logger, log.Log)
+}
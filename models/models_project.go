@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/imdario/mergo"
+	"gopkg.in/yaml.v3"
 )
 
 // Create a project
@@ -41,3 +42,9 @@ func (prj *project) Vars() map[string]interface{} {
 func (prj *project) MergeVars(vars *map[string]interface{}) {
 	_ = mergo.Merge(&prj.vars, vars, mergo.WithOverride)
 }
+
+// Project config layer, deep merged into project vars
+type ProjectLayer struct {
+	File string     // Layer file, if any (set values are not)
+	Node *yaml.Node // Layer raw document
+}
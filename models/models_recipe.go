@@ -15,6 +15,7 @@ func NewRecipe(name string, description string, dir string, repository Repositor
 		syncUnits:   []RecipeSyncUnit{},
 		schema:      map[string]interface{}{},
 		options:     []RecipeOption{},
+		env:         []string{},
 	}
 }
 
@@ -32,17 +33,29 @@ type RecipeInterface interface {
 	Options() []RecipeOption
 	AddOptions(options []RecipeOption)
 	HasOptions() bool
+	Env() []string
+	AddEnv(env []string)
+	Normalization() RecipeNormalization
+	SetNormalization(normalization RecipeNormalization)
+	Metadata() RecipeMetadata
+	SetMetadata(metadata RecipeMetadata)
+	Requires() string
+	SetRequires(requires string)
 }
 
 type recipe struct {
-	name        string
-	description string
-	dir         string
-	repository  RepositoryInterface
-	vars        map[string]interface{}
-	syncUnits   []RecipeSyncUnit
-	schema      map[string]interface{}
-	options     []RecipeOption
+	name          string
+	description   string
+	dir           string
+	repository    RepositoryInterface
+	vars          map[string]interface{}
+	syncUnits     []RecipeSyncUnit
+	schema        map[string]interface{}
+	options       []RecipeOption
+	env           []string
+	normalization RecipeNormalization
+	metadata      RecipeMetadata
+	requires      string
 }
 
 func (rec *recipe) Name() string {
@@ -100,9 +113,69 @@ func (rec *recipe) HasOptions() bool {
 	return true
 }
 
+func (rec *recipe) Env() []string {
+	return rec.env
+}
+
+func (rec *recipe) AddEnv(env []string) {
+	rec.env = append(rec.env, env...)
+}
+
+func (rec *recipe) Normalization() RecipeNormalization {
+	return rec.normalization
+}
+
+func (rec *recipe) SetNormalization(normalization RecipeNormalization) {
+	rec.normalization = normalization
+}
+
+func (rec *recipe) Metadata() RecipeMetadata {
+	return rec.metadata
+}
+
+func (rec *recipe) SetMetadata(metadata RecipeMetadata) {
+	rec.metadata = metadata
+}
+
+// Required manala version constraint, if any
+func (rec *recipe) Requires() string {
+	return rec.requires
+}
+
+func (rec *recipe) SetRequires(requires string) {
+	rec.requires = requires
+}
+
 type RecipeSyncUnit struct {
 	Source      string
 	Destination string
+	Foreach     string
+	Strategy    string `validate:"omitempty,oneof=overwrite block merge"`
+}
+
+// Normalization applied to rendered templates
+type RecipeNormalization struct {
+	Eol                    string `validate:"omitempty,oneof=lf crlf"`
+	TrimTrailingWhitespace bool   `mapstructure:"trim_trailing_whitespace"`
+	FinalNewline           bool   `mapstructure:"final_newline"`
+}
+
+// Recipe metadata, mostly informative
+type RecipeMetadata struct {
+	Tags        []string
+	Maintainers []string
+	Homepage    string
+	Deprecated  string // Deprecation message, if any
+	ReplacedBy  string // Replacing recipe name, if any
+}
+
+func (metadata RecipeMetadata) HasTag(tag string) bool {
+	for _, t := range metadata.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 type RecipeOption struct {
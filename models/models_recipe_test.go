@@ -46,6 +46,9 @@ func (s *RecipeTestSuite) TestRecipe() {
 	s.Len(rec.Vars(), 0)
 	s.Len(rec.SyncUnits(), 0)
 	s.Len(rec.Schema(), 0)
+	s.Len(rec.Env(), 0)
+	s.Equal(RecipeNormalization{}, rec.Normalization())
+	s.Equal(RecipeMetadata{}, rec.Metadata())
 }
 
 func (s *RecipeTestSuite) TestRecipeVars() {
@@ -89,3 +92,32 @@ func (s *RecipeTestSuite) TestRecipeOptions() {
 	s.True(rec.HasOptions())
 	s.Equal(options, rec.Options())
 }
+
+func (s *RecipeTestSuite) TestRecipeEnv() {
+	rec := NewRecipe(s.name, s.description, s.dir, s.repository)
+	env := []string{"FOO", "BAR"}
+	rec.AddEnv(env)
+	s.Equal(env, rec.Env())
+}
+
+func (s *RecipeTestSuite) TestRecipeNormalization() {
+	rec := NewRecipe(s.name, s.description, s.dir, s.repository)
+	normalization := RecipeNormalization{Eol: "lf", TrimTrailingWhitespace: true, FinalNewline: true}
+	rec.SetNormalization(normalization)
+	s.Equal(normalization, rec.Normalization())
+}
+
+func (s *RecipeTestSuite) TestRecipeMetadata() {
+	rec := NewRecipe(s.name, s.description, s.dir, s.repository)
+	metadata := RecipeMetadata{
+		Tags:        []string{"php", "docker"},
+		Maintainers: []string{"Foo <foo@example.com>"},
+		Homepage:    "https://example.com",
+		Deprecated:  "use bar instead",
+		ReplacedBy:  "bar",
+	}
+	rec.SetMetadata(metadata)
+	s.Equal(metadata, rec.Metadata())
+	s.True(rec.Metadata().HasTag("php"))
+	s.False(rec.Metadata().HasTag("node"))
+}
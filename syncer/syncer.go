@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"crypto/md5"
 	"fmt"
-	"github.com/Masterminds/sprig/v3"
 	"github.com/apex/log"
+	"github.com/go-git/go-git/v5"
 	"gopkg.in/yaml.v3"
 	"io"
 	"io/ioutil"
@@ -13,7 +13,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 )
@@ -30,46 +32,509 @@ func (e *SourceNotExistError) Error() string {
 	return "no source " + e.Source + " file or directory "
 }
 
+// Template error, located in its source file
+type TemplateError struct {
+	Path        string
+	Line        int // One based, zero if unknown
+	Column      int // One based, zero if unknown
+	Message     string
+	Source      string // Offending source line
+	Suggestions []string
+}
+
+func (e *TemplateError) Error() string {
+	var buf strings.Builder
+
+	buf.WriteString("invalid template \"" + e.Path + "\"")
+	if e.Line > 0 {
+		buf.WriteString(fmt.Sprintf(" at line %d", e.Line))
+		if e.Column > 0 {
+			buf.WriteString(fmt.Sprintf(", column %d", e.Column))
+		}
+	}
+	buf.WriteString(" (" + e.Message + ")")
+
+	// Offending source line, with a caret under the column
+	if e.Line > 0 {
+		gutter := fmt.Sprintf("%d | ", e.Line)
+		buf.WriteString("\n" + gutter + e.Source)
+		if e.Column > 0 {
+			buf.WriteString("\n" + strings.Repeat(" ", len(gutter)-2) + "| ")
+			for i, r := range e.Source {
+				if i >= e.Column-1 {
+					break
+				}
+				if r == '\t' {
+					buf.WriteRune('\t')
+				} else {
+					buf.WriteRune(' ')
+				}
+			}
+			buf.WriteString("^")
+		}
+	}
+
+	if len(e.Suggestions) > 0 {
+		buf.WriteString("\ndid you mean " + strings.Join(e.Suggestions, ", ") + "?")
+	}
+
+	return buf.String()
+}
+
 /********/
 /* Sync */
 /********/
 
+// Project sync options
+type Options struct {
+	// Report changes, without making them
+	DryRun bool
+	// Logger, defaulting to the global one
+	Logger log.Interface
+}
+
+// Project change, made, or to be made in dry run mode, by a sync
+type Change struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // Either "created", "updated" or "removed"
+}
+
+// Sync run, shared by all nodes of a project sync
+type syncRun struct {
+	dryRun  bool
+	changes []Change
+	logger  log.Interface
+	// Foreach units generated files, indexed by units (their sources and destinations),
+	// as recorded by previous sync, and as generated by this one
+	manifest  map[string][]string
+	generated map[string][]string
+}
+
+func (run *syncRun) isDryRun() bool {
+	return run != nil && run.dryRun
+}
+
+func (run *syncRun) log() log.Interface {
+	if run == nil || run.logger == nil {
+		return log.Log
+	}
+	return run.logger
+}
+
+func (run *syncRun) change(path string, action string) {
+	if run != nil {
+		run.changes = append(run.changes, Change{Path: path, Action: action})
+	}
+}
+
+func (run *syncRun) generate(unit string, files []string) {
+	if run != nil && len(files) > 0 {
+		run.generated[unit] = files
+	}
+}
+
+// Get foreach units files generated by previous sync, but by none of this one units,
+// except project own files, never considered as orphans
+func (run *syncRun) orphans() []string {
+	if run == nil {
+		return nil
+	}
+
+	generated := make(map[string]bool)
+	for _, files := range run.generated {
+		for _, file := range files {
+			generated[file] = true
+		}
+	}
+
+	var orphans []string
+	for _, files := range run.manifest {
+		for _, file := range files {
+			file = path.Clean(file)
+			if generated[file] || projectFiles[file] || file == "." || file == ".." || strings.HasPrefix(file, "../") || path.IsAbs(file) {
+				continue
+			}
+			generated[file] = true // Only once
+			orphans = append(orphans, file)
+		}
+	}
+	sort.Strings(orphans)
+
+	return orphans
+}
+
+// Sync manifest, recording foreach units generated files, relative to project directory
+var syncManifestFile = ".manala.sync.yaml"
+
+// Project files, never removed by a sync
+var projectFiles = map[string]bool{
+	".manala.yaml":       true,
+	".manala.local.yaml": true,
+	".manala.env":        true,
+	syncManifestFile:     true,
+}
+
+func loadSyncManifest(dir string) (map[string][]string, error) {
+	manifest := map[string][]string{}
+
+	file := filepath.Join(dir, syncManifestFile)
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid sync manifest \"%s\" (%w)", file, err)
+	}
+
+	return manifest, nil
+}
+
+func writeSyncManifest(dir string, manifest map[string][]string) error {
+	file := filepath.Join(dir, syncManifestFile)
+
+	// No more generated files, no more manifest
+	if len(manifest) == 0 {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	content, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append([]byte("# Generated by manala, do not edit\n"), content...), 0666)
+}
+
 // Sync a project from a recipe
-func SyncProject(prj models.ProjectInterface) error {
+func SyncProject(prj models.ProjectInterface, version string) error {
+	_, err := SyncProjectOptions(prj, version, Options{})
+	return err
+}
+
+// Sync a project from a recipe, following options, and get its changes
+func SyncProjectOptions(prj models.ProjectInterface, version string, options Options) ([]Change, error) {
+	manifest, err := loadSyncManifest(prj.Dir())
+	if err != nil {
+		return nil, err
+	}
+
+	run := &syncRun{
+		dryRun:    options.DryRun,
+		changes:   []Change{},
+		logger:    options.Logger,
+		manifest:  manifest,
+		generated: map[string][]string{},
+	}
+
 	// Template
 	tmpl := NewTemplate()
 
-	// Include helpers if any
-	helpers := path.Join(prj.Recipe().Dir(), "_helpers.tmpl")
-	if _, err := os.Stat(helpers); err == nil {
-		_, err = tmpl.ParseFiles(helpers)
+	// Include helpers if any, first from repository shared library, then from recipe
+	for _, helpers := range []struct {
+		dir     string
+		pattern string
+	}{
+		{dir: prj.Recipe().Repository().Dir(), pattern: "_helpers/*.tmpl"},
+		{dir: prj.Recipe().Dir(), pattern: "_*.tmpl"},
+	} {
+		files, err := filepath.Glob(filepath.Join(helpers.dir, helpers.pattern))
 		if err != nil {
-			return err
+			return nil, err
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := filepath.Rel(helpers.dir, file)
+			if err := parseHelper(tmpl, filepath.ToSlash(name), string(content)); err != nil {
+				// Locate parse errors, unlike collision ones
+				if tmplErr := newTemplateError(file, filepath.ToSlash(name), string(content), err, nil); tmplErr.Line > 0 {
+					return nil, tmplErr
+				}
+				return nil, err
+			}
 		}
 	}
 
+	// Context
+	ctx := projectContext(prj, version)
+
+	// Foreach units, indexed by their sources and destinations
+	foreachUnits := make(map[string]bool)
+
 	for _, sync := range prj.Recipe().SyncUnits() {
-		if err := Sync(
+		strategy := syncUnitStrategy(prj.Recipe(), sync)
+		strategy.run = run
+
+		// Loop over items
+		if sync.Foreach != "" {
+			unit := sync.Source + " " + sync.Destination
+			if foreachUnits[unit] {
+				return nil, fmt.Errorf("duplicate foreach unit \"%s\"", unit)
+			}
+			foreachUnits[unit] = true
+
+			files, err := syncForeach(
+				path.Join(prj.Recipe().Dir(), sync.Source),
+				prj.Dir(),
+				sync.Destination,
+				sync.Foreach,
+				strategy,
+				tmpl,
+				ctx,
+			)
+			if err != nil {
+				return nil, err
+			}
+			run.generate(unit, files)
+			continue
+		}
+
+		// Destination could be templated
+		dst, err := renderPath(tmpl, sync.Destination, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip empty destinations
+		if dst == "" {
+			run.log().WithField("src", sync.Source).Debug("Skipping empty destination...")
+			continue
+		}
+
+		if err := SyncStrategy(
 			path.Join(prj.Recipe().Dir(), sync.Source),
-			path.Join(prj.Dir(), sync.Destination),
+			path.Join(prj.Dir(), dst),
+			strategy,
 			tmpl,
-			map[string]interface{}{
-				"Vars": prj.Vars(),
-			},
+			ctx,
 		); err != nil {
+			return nil, err
+		}
+	}
+
+	// Remove orphans, once all units synced, so that files generated by any of them are kept
+	if err := removeOrphans(run, prj.Dir()); err != nil {
+		return nil, err
+	}
+
+	if !run.dryRun {
+		if err := writeSyncManifest(prj.Dir(), run.generated); err != nil {
+			return nil, err
+		}
+	}
+
+	return run.changes, nil
+}
+
+// Get a recipe sync unit strategy
+func syncUnitStrategy(rec models.RecipeInterface, unit models.RecipeSyncUnit) Strategy {
+	strategy := Strategy{
+		Normalization: rec.Normalization(),
+	}
+
+	switch unit.Strategy {
+	case "block":
+		// Managed blocks are named after their recipe
+		strategy.Block = rec.Name()
+	case "merge":
+		strategy.Merge = true
+	}
+
+	return strategy
+}
+
+// Sync a source with as many destinations as items, and get the files generated, relative to project directory
+func syncForeach(src string, dir string, dst string, foreach string, strategy Strategy, tmpl *template.Template, ctx map[string]interface{}) ([]string, error) {
+	value, err := evaluateTemplate(tmpl, foreach, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items, indexed by either their positions or their keys
+	var indexes []interface{}
+	var items []interface{}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Invalid:
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			indexes = append(indexes, i)
+			items = append(items, v.Index(i).Interface())
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			indexes = append(indexes, key.Interface())
+			items = append(items, v.MapIndex(key).Interface())
+		}
+	default:
+		return nil, fmt.Errorf("invalid foreach \"%s\" (%s is not iterable)", foreach, v.Kind())
+	}
+
+	// Destinations synced, indexed by their final paths
+	dstMap := make(map[string]bool)
+
+	for i, item := range items {
+		// Item context
+		itemCtx := make(map[string]interface{}, len(ctx)+2)
+		for k, v := range ctx {
+			itemCtx[k] = v
+		}
+		itemCtx["Item"] = item
+		itemCtx["Index"] = indexes[i]
+
+		itemDst, err := renderPath(tmpl, dst, itemCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip empty destinations
+		if itemDst == "" {
+			strategy.run.log().WithField("src", src).Debug("Skipping empty destination...")
+			continue
+		}
+
+		itemDst = path.Join(dir, itemDst)
+		itemDstPath := nodeDstPath(src, itemDst)
+
+		if dstMap[itemDstPath] {
+			return nil, fmt.Errorf("duplicate foreach destination \"%s\"", itemDstPath)
+		}
+		dstMap[itemDstPath] = true
+
+		// Ensure destination parent directory exists
+		if !strategy.run.isDryRun() {
+			if err := os.MkdirAll(filepath.Dir(itemDst), 0755); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := SyncStrategy(src, itemDst, strategy, tmpl, itemCtx); err != nil {
+			return nil, err
+		}
+	}
+
+	var generated []string
+	for itemDstPath := range dstMap {
+		if rel, err := filepath.Rel(dir, itemDstPath); err == nil {
+			generated = append(generated, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(generated)
+
+	return generated, nil
+}
+
+// Remove orphans, that is, files generated by previous sync, but not by this one
+func removeOrphans(run *syncRun, dir string) error {
+	for _, orphan := range run.orphans() {
+		file := filepath.Join(dir, orphan)
+		if _, err := os.Lstat(file); err != nil {
+			continue
+		}
+
+		run.change(file, "removed")
+		if run.isDryRun() {
+			continue
+		}
+
+		if err := os.RemoveAll(file); err != nil {
 			return err
 		}
+
+		run.log().WithFields(log.Fields{
+			"path": file,
+		}).Info("Removed orphan")
 	}
 
 	return nil
 }
 
+// Template context of a project
+func projectContext(prj models.ProjectInterface, version string) map[string]interface{} {
+	// Project name is derived from its directory
+	name := filepath.Base(prj.Dir())
+	if abs, err := filepath.Abs(prj.Dir()); err == nil {
+		name = filepath.Base(abs)
+	}
+
+	// Only allowed environment variables are exposed
+	env := map[string]interface{}{}
+	for _, key := range prj.Recipe().Env() {
+		env[key] = os.Getenv(key)
+	}
+
+	return map[string]interface{}{
+		"Vars": prj.Vars(),
+		"Recipe": map[string]interface{}{
+			"Name":        prj.Recipe().Name(),
+			"Description": prj.Recipe().Description(),
+			"Repository":  prj.Recipe().Repository().Src(),
+			"Commit":      repositoryCommit(prj.Recipe().Repository()),
+		},
+		"Project": map[string]interface{}{
+			"Dir":  prj.Dir(),
+			"Name": name,
+		},
+		"Manala": map[string]interface{}{
+			"Version": version,
+		},
+		"Env":   env,
+		"Files": &templateFiles{dir: prj.Recipe().Dir()},
+	}
+}
+
+// Get repository current commit hash, if any
+func repositoryCommit(repo models.RepositoryInterface) string {
+	gitRepository, err := git.PlainOpen(repo.Dir())
+	if err != nil {
+		return ""
+	}
+
+	head, err := gitRepository.Head()
+	if err != nil {
+		return ""
+	}
+
+	return head.Hash().String()
+}
+
+// Sync strategy, telling how source contents are written into their destinations
+type Strategy struct {
+	// Name of the managed block sources are confined to, leaving destinations contents outside of it untouched
+	Block string
+	// Deep merge sources documents into destinations ones
+	Merge bool
+	// Normalization applied to rendered templates
+	Normalization models.RecipeNormalization
+	// Project sync run, if any
+	run *syncRun
+}
+
 // Sync a source with a destination
 func Sync(src string, dst string, tmpl *template.Template, ctx interface{}) error {
+	return SyncStrategy(src, dst, Strategy{}, tmpl, ctx)
+}
+
+// Sync a source with a destination, following a strategy
+func SyncStrategy(src string, dst string, strategy Strategy, tmpl *template.Template, ctx interface{}) error {
 	node, err := newNode(src, dst, tmpl, ctx)
 	if err != nil {
 		return err
 	}
+	node.Strategy = strategy
 
 	if err := syncNode(node); err != nil {
 		return err
@@ -85,9 +550,10 @@ type node struct {
 		Files        []string
 		IsExecutable bool
 	}
-	IsDist bool
-	IsTmpl bool
-	Dst    struct {
+	IsDist  bool
+	IsTmpl  bool
+	IsMerge bool
+	Dst     struct {
 		Path    string
 		Mode    os.FileMode
 		Hash    []byte
@@ -95,12 +561,32 @@ type node struct {
 		IsDir   bool
 		Files   []string
 	}
+	Strategy Strategy
 	Template *template.Template
 	Context  interface{}
 }
 
 var distRegex = regexp.MustCompile(`(\.dist)(?:$|\.tmpl$)`)
 var tmplRegex = regexp.MustCompile(`(\.tmpl)(?:$|\.dist$)`)
+var mergeRegex = regexp.MustCompile(`\.merge(\.(?:yaml|yml|json))(?:$|\.tmpl$)`)
+var mergeDstRegex = regexp.MustCompile(`\.merge(\.(?:yaml|yml|json))$`)
+
+// Get destination path, without source dist, template and merge suffixes
+func nodeDstPath(src string, dst string) string {
+	if distRegex.MatchString(src) {
+		dst = distRegex.ReplaceAllString(dst, "")
+	}
+
+	if tmplRegex.MatchString(src) {
+		dst = tmplRegex.ReplaceAllString(dst, "")
+	}
+
+	if mergeRegex.MatchString(src) {
+		dst = mergeDstRegex.ReplaceAllString(dst, "$1")
+	}
+
+	return dst
+}
 
 func newNode(src string, dst string, tmpl *template.Template, cxt interface{}) (*node, error) {
 	node := &node{}
@@ -133,15 +619,10 @@ func newNode(src string, dst string, tmpl *template.Template, cxt interface{}) (
 	} else {
 		node.Src.IsExecutable = (stat.Mode() & 0100) != 0
 
-		if distRegex.MatchString(node.Src.Path) {
-			node.IsDist = true
-			node.Dst.Path = distRegex.ReplaceAllString(node.Dst.Path, "")
-		}
-
-		if tmplRegex.MatchString(node.Src.Path) {
-			node.IsTmpl = true
-			node.Dst.Path = tmplRegex.ReplaceAllString(node.Dst.Path, "")
-		}
+		node.IsDist = distRegex.MatchString(node.Src.Path)
+		node.IsTmpl = tmplRegex.MatchString(node.Src.Path)
+		node.IsMerge = mergeRegex.MatchString(node.Src.Path)
+		node.Dst.Path = nodeDstPath(node.Src.Path, node.Dst.Path)
 	}
 
 	// Destination info
@@ -192,43 +673,68 @@ func newNode(src string, dst string, tmpl *template.Template, cxt interface{}) (
 func syncNode(node *node) error {
 	if node.Src.IsDir {
 
-		log.WithFields(log.Fields{
+		node.Strategy.run.log().WithFields(log.Fields{
 			"src": node.Src.Path,
 			"dst": node.Dst.Path,
 		}).Debug("Syncing directory...")
 
+		dryRun := node.Strategy.run.isDryRun()
+
 		// Destination is a file; remove
 		if node.Dst.IsExist && !node.Dst.IsDir {
-			if err := os.Remove(node.Dst.Path); err != nil {
-				return err
+			if !dryRun {
+				if err := os.Remove(node.Dst.Path); err != nil {
+					return err
+				}
 			}
 			node.Dst.IsExist = false
 		}
 
 		// Destination does not exists; create
 		if !node.Dst.IsExist {
-			if err := os.MkdirAll(node.Dst.Path, 0755); err != nil {
-				return err
-			}
+			node.Strategy.run.change(node.Dst.Path, "created")
 
-			log.WithFields(log.Fields{
-				"path": node.Dst.Path,
-			}).Info("Synced directory")
+			if !dryRun {
+				if err := os.MkdirAll(node.Dst.Path, 0755); err != nil {
+					return err
+				}
+
+				node.Strategy.run.log().WithFields(log.Fields{
+					"path": node.Dst.Path,
+				}).Info("Synced directory")
+			}
 		}
 
 		// Iterate over source files
 		// Make a map of destination files map for quick lookup; used in deletion below
 		dstMap := make(map[string]bool)
 		for _, file := range node.Src.Files {
+			// File name could be templated
+			dstFile, err := renderPath(node.Template, file, &node.Context)
+			if err != nil {
+				return err
+			}
+
+			// Skip empty file names
+			if dstFile == "" {
+				node.Strategy.run.log().WithField("src", path.Join(node.Src.Path, file)).Debug("Skipping empty file name...")
+				continue
+			}
+
+			if strings.ContainsAny(dstFile, `/\`) {
+				return fmt.Errorf("invalid templated file name \"%s\" (%s)", file, dstFile)
+			}
+
 			fileNode, err := newNode(
 				path.Join(node.Src.Path, file),
-				path.Join(node.Dst.Path, file),
+				path.Join(node.Dst.Path, dstFile),
 				node.Template,
 				&node.Context,
 			)
 			if err != nil {
 				return err
 			}
+			fileNode.Strategy = node.Strategy
 
 			dstMap[filepath.Base(fileNode.Dst.Path)] = true
 
@@ -237,7 +743,11 @@ func syncNode(node *node) error {
 			}
 		}
 
-		// Delete not synced destination files
+		// Delete not synced destination files; none in a directory to be created
+		if !node.Dst.IsExist && dryRun {
+			return nil
+		}
+
 		files, err := ioutil.ReadDir(node.Dst.Path)
 		if err != nil {
 			return err
@@ -245,6 +755,10 @@ func syncNode(node *node) error {
 
 		for _, file := range files {
 			if !dstMap[file.Name()] {
+				node.Strategy.run.change(filepath.Join(node.Dst.Path, file.Name()), "removed")
+				if dryRun {
+					continue
+				}
 				if err := os.RemoveAll(filepath.Join(node.Dst.Path, file.Name())); err != nil {
 					return err
 				}
@@ -255,15 +769,19 @@ func syncNode(node *node) error {
 
 	} else {
 
-		log.WithFields(log.Fields{
+		node.Strategy.run.log().WithFields(log.Fields{
 			"src": node.Src.Path,
 			"dst": node.Dst.Path,
 		}).Debug("Syncing file...")
 
+		dryRun := node.Strategy.run.isDryRun()
+
 		// Destination is a directory; remove
 		if node.Dst.IsExist && node.Dst.IsDir {
-			if err := os.RemoveAll(node.Dst.Path); err != nil {
-				return err
+			if !dryRun {
+				if err := os.RemoveAll(node.Dst.Path); err != nil {
+					return err
+				}
 			}
 			node.Dst.IsExist = false
 			node.Dst.IsDir = false
@@ -274,9 +792,7 @@ func syncNode(node *node) error {
 			return nil
 		}
 
-		equal := false
-
-		var srcReader io.Reader
+		var srcReader io.ReadSeeker
 
 		if node.IsTmpl {
 			// Read template content
@@ -284,27 +800,22 @@ func syncNode(node *node) error {
 			if err != nil {
 				return err
 			}
-			// Parse
-			_, err = node.Template.Parse(string(tmplContent))
+			// Parse, into a clone of base template, so that defines don't leak from a file to another
+			tmpl, err := parseTemplate(node.Template, node.Src.Path, string(tmplContent))
 			if err != nil {
+				// Locate parse errors, unlike collision ones
+				if tmplErr := newTemplateError(node.Src.Path, node.Src.Path, string(tmplContent), err, nil); tmplErr.Line > 0 {
+					return tmplErr
+				}
 				return err
 			}
 			// Execute
 			var buffer bytes.Buffer
-			if err := node.Template.Execute(&buffer, node.Context); err != nil {
-				return fmt.Errorf("invalid template \"%s\" (%s)", node.Src.Path, err)
+			if err := tmpl.Execute(&buffer, node.Context); err != nil {
+				return newTemplateError(node.Src.Path, node.Src.Path, string(tmplContent), err, node.Context)
 			}
 
-			srcReader = bytes.NewReader(buffer.Bytes())
-
-			if node.Dst.IsExist {
-				// Get template hash
-				hash := md5.New()
-				if _, err := io.Copy(hash, &buffer); err != nil {
-					return err
-				}
-				equal = bytes.Compare(hash.Sum(nil), node.Dst.Hash) == 0
-			}
+			srcReader = bytes.NewReader(normalizeContent(buffer.Bytes(), node.Strategy.Normalization))
 		} else {
 			// Node is not a template, let's go buffering \o/
 			srcFile, err := os.Open(node.Src.Path)
@@ -313,24 +824,61 @@ func syncNode(node *node) error {
 			}
 			defer srcFile.Close()
 
-			if node.Dst.IsExist {
-				// Get source hash
-				hash := md5.New()
-				if _, err := io.Copy(hash, srcFile); err != nil {
-					return err
-				}
-				equal = bytes.Compare(hash.Sum(nil), node.Dst.Hash) == 0
+			srcReader = srcFile
+		}
 
-				if _, err := srcFile.Seek(0, io.SeekStart); err != nil {
-					return err
-				}
+		// Managed block; only replace its region in destination content
+		if node.Strategy.Block != "" {
+			block, err := ioutil.ReadAll(srcReader)
+			if err != nil {
+				return err
 			}
+			content, err := blockContent(node.Dst.Path, node.Dst.IsExist, node.Strategy.Block, block)
+			if err != nil {
+				return err
+			}
+			srcReader = bytes.NewReader(content)
+		}
 
-			srcReader = srcFile
+		// Structured document; deep merge it into destination one
+		if node.IsMerge || node.Strategy.Merge {
+			document, err := ioutil.ReadAll(srcReader)
+			if err != nil {
+				return err
+			}
+			content, err := mergeContent(node.Dst.Path, node.Dst.IsExist, document)
+			if err != nil {
+				return err
+			}
+			srcReader = bytes.NewReader(content)
+		}
+
+		equal := false
+
+		if node.Dst.IsExist {
+			// Get source hash
+			hash := md5.New()
+			if _, err := io.Copy(hash, srcReader); err != nil {
+				return err
+			}
+			equal = bytes.Compare(hash.Sum(nil), node.Dst.Hash) == 0
+
+			if _, err := srcReader.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
 		}
 
 		// Files are not equals or destination does not exists
 		if !equal {
+			if node.Dst.IsExist {
+				node.Strategy.run.change(node.Dst.Path, "updated")
+			} else {
+				node.Strategy.run.change(node.Dst.Path, "created")
+			}
+			if dryRun {
+				return nil
+			}
+
 			// Destination file mode
 			var dstMode os.FileMode = 0666
 			if node.Src.IsExecutable {
@@ -350,16 +898,21 @@ func syncNode(node *node) error {
 				return err
 			}
 
-			log.WithFields(log.Fields{
+			node.Strategy.run.log().WithFields(log.Fields{
 				"path": node.Dst.Path,
 			}).Info("Synced file")
-		} else {
+		} else if node.Strategy.Block == "" && !node.IsMerge && !node.Strategy.Merge {
+			// Destination mode of managed blocks and merged documents is left to user
 			dstMode := node.Dst.Mode &^ 0111
 			if node.Src.IsExecutable {
 				dstMode = node.Dst.Mode | 0111
 			}
 
 			if dstMode != node.Dst.Mode {
+				node.Strategy.run.change(node.Dst.Path, "updated")
+				if dryRun {
+					return nil
+				}
 				if err := os.Chmod(node.Dst.Path, dstMode); err != nil {
 					return err
 				}
@@ -369,59 +922,3 @@ func syncNode(node *node) error {
 		return nil
 	}
 }
-
-/************/
-/* Template */
-/************/
-
-func NewTemplate() *template.Template {
-	tmpl := template.New("")
-
-	// Execution stops immediately with an error.
-	tmpl.Option("missingkey=error")
-
-	tmpl.Funcs(sprig.TxtFuncMap())
-	tmpl.Funcs(template.FuncMap{
-		"toYaml":  templateToYamlFunc(),
-		"include": templateIncludeFunc(tmpl),
-	})
-
-	return tmpl
-}
-
-// As seen in helm
-func templateToYamlFunc() func(value interface{}) string {
-	return func(value interface{}) string {
-		var buf bytes.Buffer
-
-		enc := yaml.NewEncoder(&buf)
-
-		if err := enc.Encode(value); err != nil {
-			// Swallow errors inside of a template.
-			return ""
-		}
-
-		return strings.TrimSuffix(buf.String(), "\n")
-	}
-}
-
-// As seen in helm
-func templateIncludeFunc(tmpl *template.Template) func(name string, data interface{}) (string, error) {
-	includedNames := make([]string, 0)
-	return func(name string, data interface{}) (string, error) {
-		var buf strings.Builder
-		includedCount := 0
-		for _, n := range includedNames {
-			if n == name {
-				includedCount += 1
-			}
-		}
-		if includedCount >= 16 {
-			return "", fmt.Errorf("rendering template has reached the maximum nested reference name level: %s", name)
-		}
-		includedNames = append(includedNames, name)
-		err := tmpl.ExecuteTemplate(&buf, name, data)
-		includedNames = includedNames[:len(includedNames)-1]
-		return buf.String(), err
-	}
-}
@@ -0,0 +1,110 @@
+package syncer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+/*********/
+/* Block */
+/*********/
+
+// Comment delimiters, indexed by file extensions
+var blockCommentDelimiters = map[string][2]string{
+	".c":    {"//", ""},
+	".css":  {"/*", " */"},
+	".go":   {"//", ""},
+	".h":    {"//", ""},
+	".htm":  {"<!--", " -->"},
+	".html": {"<!--", " -->"},
+	".ini":  {";", ""},
+	".java": {"//", ""},
+	".js":   {"//", ""},
+	".lua":  {"--", ""},
+	".md":   {"<!--", " -->"},
+	".php":  {"//", ""},
+	".scss": {"//", ""},
+	".sql":  {"--", ""},
+	".ts":   {"//", ""},
+	".twig": {"{#", " #}"},
+	".vcl":  {"#", ""},
+	".xml":  {"<!--", " -->"},
+}
+
+// Get block markers, commented according to file extension (defaults to "#")
+func blockMarkers(path string, name string) (string, string) {
+	delimiters, ok := blockCommentDelimiters[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		delimiters = [2]string{"#", ""}
+	}
+
+	return delimiters[0] + " manala:begin " + name + delimiters[1],
+		delimiters[0] + " manala:end" + delimiters[1]
+}
+
+// Get destination content, with named block region replaced (or appended)
+func blockContent(path string, isExist bool, name string, block []byte) ([]byte, error) {
+	begin, end := blockMarkers(path, name)
+
+	// Ensure block ends with a new line
+	if len(block) > 0 && !bytes.HasSuffix(block, []byte("\n")) {
+		block = append(block, '\n')
+	}
+
+	var content []byte
+	if isExist {
+		var err error
+		content, err = ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+
+	// Look for existing markers
+	beginIndex, endIndex := -1, -1
+	for i, line := range lines {
+		if beginIndex == -1 {
+			if strings.TrimSpace(line) == begin {
+				beginIndex = i
+			}
+		} else if strings.TrimSpace(line) == end {
+			endIndex = i
+			break
+		}
+	}
+
+	if beginIndex != -1 && endIndex == -1 {
+		return nil, fmt.Errorf("unterminated block \"%s\" in \"%s\"", name, path)
+	}
+
+	var buf bytes.Buffer
+
+	// Block not found; append it
+	if beginIndex == -1 {
+		buf.Write(content)
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(begin + "\n")
+		buf.Write(block)
+		buf.WriteString(end + "\n")
+
+		return buf.Bytes(), nil
+	}
+
+	// Replace region between markers, keeping them as is
+	for _, line := range lines[:beginIndex+1] {
+		buf.WriteString(line)
+	}
+	buf.Write(block)
+	for _, line := range lines[endIndex:] {
+		buf.WriteString(line)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,224 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+/*********/
+/* Merge */
+/*********/
+
+// Get destination content, with source document deep merged into it.
+// Format (yaml or json) is based on destination extension.
+func mergeContent(path string, isExist bool, document []byte) ([]byte, error) {
+	var isJson bool
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		isJson = true
+	case ".yaml", ".yml":
+		isJson = false
+	default:
+		return nil, fmt.Errorf("unable to merge \"%s\" (unsupported format)", path)
+	}
+
+	// Nothing to merge into
+	if !isExist {
+		return document, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Json being a subset of yaml, both are parsed the same way
+	dstNode := yaml.Node{}
+	if err := yaml.Unmarshal(content, &dstNode); err != nil {
+		return nil, fmt.Errorf("invalid merge destination \"%s\" (%s)", path, err)
+	}
+	if len(dstNode.Content) == 0 {
+		return document, nil
+	}
+
+	srcNode := yaml.Node{}
+	if err := yaml.Unmarshal(document, &srcNode); err != nil {
+		return nil, fmt.Errorf("invalid merged document for \"%s\" (%s)", path, err)
+	}
+	if len(srcNode.Content) == 0 {
+		return content, nil
+	}
+
+	mergeNodes(dstNode.Content[0], srcNode.Content[0])
+
+	// Keep destination indentation, or fallback to source one
+	indent := mergeIndent(content)
+	if indent == 0 {
+		indent = mergeIndent(document)
+	}
+
+	var buf bytes.Buffer
+
+	if isJson {
+		if indent == 0 {
+			indent = 2
+		}
+		if err := encodeJsonNode(&buf, dstNode.Content[0], strings.Repeat(" ", indent), 0); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n")
+
+		return buf.Bytes(), nil
+	}
+
+	if indent < 2 {
+		indent = 4
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(&dstNode); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Deep merge source node into destination one.
+// Mappings are merged key by key, destination keys being kept; anything else is replaced by source.
+func mergeNodes(dst *yaml.Node, src *yaml.Node) {
+	for src.Kind == yaml.AliasNode {
+		src = src.Alias
+	}
+
+	if dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+
+			found := false
+			for j := 0; j+1 < len(dst.Content); j += 2 {
+				if dst.Content[j].Value == key.Value {
+					mergeNodes(dst.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+
+		return
+	}
+
+	// Source wins, but destination comments are kept, unless source has its own
+	headComment, lineComment, footComment := dst.HeadComment, dst.LineComment, dst.FootComment
+
+	*dst = *src
+
+	if dst.HeadComment == "" {
+		dst.HeadComment = headComment
+	}
+	if dst.LineComment == "" {
+		dst.LineComment = lineComment
+	}
+	if dst.FootComment == "" {
+		dst.FootComment = footComment
+	}
+}
+
+// Detect document indentation, based on its first indented line
+func mergeIndent(content []byte) int {
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			continue
+		}
+		if indent := len(line) - len(trimmed); indent > 0 {
+			return indent
+		}
+	}
+
+	return 0
+}
+
+// Encode a node as json, keeping its keys order
+func encodeJsonNode(buf *bytes.Buffer, node *yaml.Node, indent string, depth int) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeJsonNode(buf, node.Content[0], indent, depth)
+	case yaml.AliasNode:
+		return encodeJsonNode(buf, node.Alias, indent, depth)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(strings.Repeat(indent, depth+1))
+			if err := encodeJsonValue(buf, node.Content[i].Value); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := encodeJsonNode(buf, node.Content[i+1], indent, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + strings.Repeat(indent, depth) + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(strings.Repeat(indent, depth+1))
+			if err := encodeJsonNode(buf, item, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + strings.Repeat(indent, depth) + "]")
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		return encodeJsonValue(buf, value)
+	}
+
+	return nil
+}
+
+// Encode a single json value, without escaping html characters
+func encodeJsonValue(buf *bytes.Buffer, value interface{}) error {
+	var valueBuf bytes.Buffer
+
+	enc := json.NewEncoder(&valueBuf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Errorf("unable to encode json: %w", err)
+	}
+
+	buf.Write(bytes.TrimSuffix(valueBuf.Bytes(), []byte("\n")))
+
+	return nil
+}
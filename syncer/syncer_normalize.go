@@ -0,0 +1,56 @@
+package syncer
+
+import (
+	"manala/models"
+	"strings"
+)
+
+/*************/
+/* Normalize */
+/*************/
+
+// Normalize content line endings, trailing whitespaces and final newline
+func normalizeContent(content []byte, normalization models.RecipeNormalization) []byte {
+	if normalization == (models.RecipeNormalization{}) || len(content) == 0 {
+		return content
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	// Line ending, defaulting to previous one
+	eol := "\n"
+
+	var buf strings.Builder
+	for i, line := range lines {
+		if strings.HasSuffix(line, "\r") {
+			eol = "\r\n"
+			line = strings.TrimSuffix(line, "\r")
+		} else if i < len(lines)-1 {
+			eol = "\n"
+		}
+		switch normalization.Eol {
+		case "lf":
+			eol = "\n"
+		case "crlf":
+			eol = "\r\n"
+		}
+
+		if normalization.TrimTrailingWhitespace {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		buf.WriteString(line)
+
+		// Last line
+		if i == len(lines)-1 {
+			if normalization.FinalNewline && line != "" {
+				buf.WriteString(eol)
+			}
+			break
+		}
+
+		buf.WriteString(eol)
+	}
+
+	return []byte(buf.String())
+}
@@ -0,0 +1,499 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/sprig/v3"
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"manala/yaml/cleaner"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+/************/
+/* Template */
+/************/
+
+func NewTemplate() *template.Template {
+	tmpl := template.New("")
+
+	// Execution stops immediately with an error.
+	tmpl.Option("missingkey=error")
+
+	tmpl.Funcs(sprig.TxtFuncMap())
+	tmpl.Funcs(template.FuncMap{
+		"toYaml":   templateToYamlFunc(),
+		"fromYaml": templateFromYamlFunc(),
+		"toJson":   templateToJsonFunc(),
+		"fromJson": templateFromJsonFunc(),
+		"toToml":   templateToTomlFunc(),
+		"required": templateRequiredFunc(),
+		"include":  templateIncludeFunc(tmpl),
+		"tpl":      templateTplFunc(tmpl),
+	})
+
+	return tmpl
+}
+
+// As seen in helm
+func templateToYamlFunc() func(value interface{}) string {
+	return func(value interface{}) string {
+		var buf bytes.Buffer
+
+		enc := yaml.NewEncoder(&buf)
+
+		if err := enc.Encode(value); err != nil {
+			// Swallow errors inside of a template.
+			return ""
+		}
+
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+}
+
+// As seen in helm, except that errors are not swallowed
+func templateFromYamlFunc() func(str string) (map[string]interface{}, error) {
+	return func(str string) (map[string]interface{}, error) {
+		value := map[string]interface{}{}
+
+		if err := yaml.Unmarshal([]byte(str), &value); err != nil {
+			return nil, fmt.Errorf("unable to decode yaml: %w", err)
+		}
+
+		// See: https://github.com/go-yaml/yaml/issues/139
+		return cleaner.Clean(value), nil
+	}
+}
+
+// As seen in helm, except that errors are not swallowed
+func templateToJsonFunc() func(value interface{}) (string, error) {
+	return func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode json: %w", err)
+		}
+
+		return string(data), nil
+	}
+}
+
+// As seen in helm, except that errors are not swallowed
+func templateFromJsonFunc() func(str string) (map[string]interface{}, error) {
+	return func(str string) (map[string]interface{}, error) {
+		value := map[string]interface{}{}
+
+		if err := json.Unmarshal([]byte(str), &value); err != nil {
+			return nil, fmt.Errorf("unable to decode json: %w", err)
+		}
+
+		return value, nil
+	}
+}
+
+// As seen in helm, except that errors are not swallowed
+func templateToTomlFunc() func(value interface{}) (string, error) {
+	return func(value interface{}) (string, error) {
+		data, err := toml.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode toml: %w", err)
+		}
+
+		return string(data), nil
+	}
+}
+
+// As seen in helm
+func templateRequiredFunc() func(warn string, value interface{}) (interface{}, error) {
+	return func(warn string, value interface{}) (interface{}, error) {
+		if value == nil {
+			return value, errors.New(warn)
+		} else if str, ok := value.(string); ok && str == "" {
+			return value, errors.New(warn)
+		}
+
+		return value, nil
+	}
+}
+
+// As seen in helm
+func templateIncludeFunc(tmpl *template.Template) func(name string, data interface{}) (string, error) {
+	includedNames := make([]string, 0)
+	return func(name string, data interface{}) (string, error) {
+		var buf strings.Builder
+		includedCount := 0
+		for _, n := range includedNames {
+			if n == name {
+				includedCount += 1
+			}
+		}
+		if includedCount >= 16 {
+			return "", fmt.Errorf("rendering template has reached the maximum nested reference name level: %s", name)
+		}
+		includedNames = append(includedNames, name)
+		err := tmpl.ExecuteTemplate(&buf, name, data)
+		includedNames = includedNames[:len(includedNames)-1]
+		return buf.String(), err
+	}
+}
+
+// As seen in helm
+func templateTplFunc(tmpl *template.Template) func(text string, data interface{}) (string, error) {
+	return func(text string, data interface{}) (string, error) {
+		// Work on a clone, so that parsed text does not leak into template
+		clone, err := cloneTemplate(tmpl)
+		if err != nil {
+			return "", err
+		}
+
+		clone, err = clone.New("tpl").Parse(text)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse template text: %w", err)
+		}
+
+		var buf strings.Builder
+		if err := clone.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("unable to execute template text: %w", err)
+		}
+
+		return buf.String(), nil
+	}
+}
+
+// Clone a template, including its helpers and functions
+func cloneTemplate(tmpl *template.Template) (*template.Template, error) {
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("unable to clone template: %w", err)
+	}
+
+	// Functions relying on template must now rely on its clone
+	clone.Funcs(template.FuncMap{
+		"include": templateIncludeFunc(clone),
+		"tpl":     templateTplFunc(clone),
+	})
+
+	return clone, nil
+}
+
+// Parse a named helper text into template.
+// Helpers are not allowed to redefine templates already defined by other ones.
+func parseHelper(tmpl *template.Template, name string, text string) error {
+	// Templates already defined, along with the name of their helpers
+	defined := map[string]string{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			defined[t.Name()] = t.Tree.ParseName
+		}
+	}
+
+	if _, err := tmpl.New(name).Parse(text); err != nil {
+		return err
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Name() == name || t.Tree == nil || t.Tree.ParseName != name {
+			continue
+		}
+		if parseName, ok := defined[t.Name()]; ok {
+			return fmt.Errorf("template \"%s\" defined in \"%s\" is already defined in \"%s\"", t.Name(), name, parseName)
+		}
+	}
+
+	return nil
+}
+
+// Parse a named text into a clone of base template.
+// Text is not allowed to redefine templates already defined in base template.
+func parseTemplate(base *template.Template, name string, text string) (*template.Template, error) {
+	clone, err := cloneTemplate(base)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := clone.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look for defines replacing base ones
+	for _, t := range tmpl.Templates() {
+		if t.Name() == name || t.Tree == nil || t.Tree.ParseName != name {
+			continue
+		}
+		if baseT := base.Lookup(t.Name()); baseT != nil && baseT.Tree != nil {
+			return nil, fmt.Errorf("template \"%s\" defined in \"%s\" is already defined in \"%s\"", t.Name(), name, baseT.Tree.ParseName)
+		}
+	}
+
+	return tmpl, nil
+}
+
+// Parse a template file, without executing it.
+// Parse errors are located the same way sync ones are.
+func ParseTemplateFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := parseTemplate(NewTemplate(), path, string(content)); err != nil {
+		return newTemplateError(path, path, string(content), err, nil)
+	}
+
+	return nil
+}
+
+// Evaluate a template pipeline, such as ".Vars.foo", and get its raw value
+func evaluateTemplate(tmpl *template.Template, pipeline string, ctx interface{}) (interface{}, error) {
+	clone, err := cloneTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	clone.Funcs(template.FuncMap{
+		"__evaluate": func(v interface{}) string {
+			value = v
+			return ""
+		},
+	})
+
+	clone, err = clone.New(pipeline).Parse("{{ __evaluate (" + pipeline + ") }}")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline \"%s\" (%s)", pipeline, err)
+	}
+
+	if err := clone.Execute(ioutil.Discard, ctx); err != nil {
+		return nil, fmt.Errorf("invalid pipeline \"%s\" (%s)", pipeline, err)
+	}
+
+	return value, nil
+}
+
+// Render a path possibly containing template actions
+func renderPath(tmpl *template.Template, path string, ctx interface{}) (string, error) {
+	if !strings.Contains(path, "{{") {
+		return path, nil
+	}
+
+	clone, err := cloneTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	clone, err = clone.New(path).Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid templated path \"%s\" (%s)", path, err)
+	}
+
+	var buf strings.Builder
+	if err := clone.Execute(&buf, ctx); err != nil {
+		return "", fmt.Errorf("invalid templated path \"%s\" (%s)", path, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Give templates a read access to raw files, as seen in helm
+type templateFiles struct {
+	dir string
+}
+
+// Get file content
+func (files *templateFiles) Get(name string) (string, error) {
+	path, err := files.path(name)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read file \"%s\"", name)
+	}
+
+	return string(content), nil
+}
+
+// Get files contents, indexed by their names
+func (files *templateFiles) Glob(pattern string) (map[string]string, error) {
+	path, err := files.path(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid files pattern \"%s\"", pattern)
+	}
+
+	sort.Strings(matches)
+
+	contents := map[string]string{}
+	for _, match := range matches {
+		name, _ := filepath.Rel(files.dir, match)
+		content, err := files.Get(name)
+		if err != nil {
+			// Skip directories
+			continue
+		}
+		contents[filepath.ToSlash(name)] = content
+	}
+
+	return contents, nil
+}
+
+// Ensure name stays relative to files directory
+func (files *templateFiles) path(name string) (string, error) {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file \"%s\" out of recipe", name)
+	}
+
+	return filepath.Join(files.dir, name), nil
+}
+
+var templateErrorExecutingRegex = regexp.MustCompile(`^executing "[^"]*" `)
+var templateErrorMissingKeyRegex = regexp.MustCompile(`at <(\.[\w.]+)>: map has no entry for key "[^"]*"$`)
+
+// Locate a template error in its source text, named after path.
+// Missing keys come with the closest existing context paths.
+func newTemplateError(path string, name string, text string, err error, ctx interface{}) *TemplateError {
+	tmplErr := &TemplateError{
+		Path:    path,
+		Message: err.Error(),
+	}
+
+	// Text/template errors are formatted as "template: NAME:LINE[:COLUMN]: MESSAGE"
+	matches := regexp.MustCompile(`(?s)^template: ` + regexp.QuoteMeta(name) + `:(\d+)(?::(\d+))?: (.*)$`).FindStringSubmatch(err.Error())
+	if matches == nil {
+		return tmplErr
+	}
+
+	tmplErr.Line, _ = strconv.Atoi(matches[1])
+	if matches[2] != "" {
+		// Columns are zero based byte offsets
+		column, _ := strconv.Atoi(matches[2])
+		tmplErr.Column = column + 1
+	}
+	tmplErr.Message = templateErrorExecutingRegex.ReplaceAllString(matches[3], "")
+
+	lines := strings.Split(text, "\n")
+	if tmplErr.Line <= len(lines) {
+		tmplErr.Source = strings.TrimRight(lines[tmplErr.Line-1], "\r")
+	}
+
+	if matches := templateErrorMissingKeyRegex.FindStringSubmatch(tmplErr.Message); matches != nil {
+		tmplErr.Suggestions = templateSuggestions(matches[1], ctx)
+	}
+
+	return tmplErr
+}
+
+// Get the context paths closest to a missing one
+func templateSuggestions(missing string, ctx interface{}) []string {
+	type suggestion struct {
+		path     string
+		distance int
+	}
+
+	threshold := len(missing) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	missingDepth := strings.Count(missing, ".")
+
+	var suggestions []suggestion
+	for _, path := range templateContextPaths(reflect.ValueOf(ctx), "", 0) {
+		distance := levenshtein(missing, path)
+
+		// Missing path could be relative to a nested dot, as in "with" or "range" actions
+		if parts := strings.Split(path, "."); len(parts) > missingDepth+1 {
+			if d := levenshtein(missing, "."+strings.Join(parts[len(parts)-missingDepth:], ".")); d < distance {
+				distance = d
+			}
+		}
+
+		if distance <= threshold {
+			suggestions = append(suggestions, suggestion{path: path, distance: distance})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].path < suggestions[j].path
+	})
+
+	var paths []string
+	for i, s := range suggestions {
+		if i >= 3 {
+			break
+		}
+		paths = append(paths, s.path)
+	}
+
+	return paths
+}
+
+// Flatten context maps into dotted paths, such as ".Vars.foo.bar"
+func templateContextPaths(value reflect.Value, prefix string, depth int) []string {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() || value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String || depth > 8 {
+		return nil
+	}
+
+	var paths []string
+	for _, key := range value.MapKeys() {
+		path := prefix + "." + key.String()
+		paths = append(paths, path)
+		paths = append(paths, templateContextPaths(value.MapIndex(key), path, depth+1)...)
+	}
+
+	return paths
+}
+
+// Levenshtein distance between two strings
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cur := row[j]
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			row[j] = row[j] + 1
+			if row[j-1]+1 < row[j] {
+				row[j] = row[j-1] + 1
+			}
+			if prev+cost < row[j] {
+				row[j] = prev + cost
+			}
+			prev = cur
+		}
+	}
+
+	return row[len(rb)]
+}
@@ -1,10 +1,13 @@
 package syncer
 
 import (
+	"errors"
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/discard"
+	"github.com/apex/log/handlers/memory"
 	"github.com/stretchr/testify/suite"
 	"io/ioutil"
+	"manala/models"
 	"os"
 	"testing"
 )
@@ -168,6 +171,250 @@ func (s *SyncExecutableTestSuite) TestSyncExecutableSourceTrueDestinationTrue()
 	s.Equal(true, (stat.Mode()&0100) != 0)
 }
 
+/**********************/
+/* Sync Block - Suite */
+/**********************/
+
+type SyncBlockTestSuite struct{ suite.Suite }
+
+func TestSyncBlockTestSuite(t *testing.T) {
+	// Discard logs
+	log.SetHandler(discard.Default)
+	// Run
+	suite.Run(t, new(SyncBlockTestSuite))
+}
+
+func (s *SyncBlockTestSuite) SetupTest() {
+	dir := "testdata/sync_block/destination"
+	_ = os.RemoveAll(dir)
+	_ = os.Mkdir(dir, 0755)
+	_ = ioutil.WriteFile(dir+"/Makefile_user", []byte("bar:\n\t@echo bar"), 0666)
+	_ = ioutil.WriteFile(dir+"/Makefile_block", []byte("bar:\n\t@echo bar\n\n  # manala:begin foo\nbaz:\n\t@echo baz\n# manala:end\n\nqux:\n\t@echo qux\n"), 0666)
+	_ = ioutil.WriteFile(dir+"/Makefile_unterminated", []byte("# manala:begin foo\nbaz:\n"), 0666)
+}
+
+/**********************/
+/* Sync Block - Tests */
+/**********************/
+
+func (s *SyncBlockTestSuite) TestSyncBlockDestinationNotExists() {
+	err := SyncStrategy("testdata/sync_block/source/Makefile", "testdata/sync_block/destination/Makefile", Strategy{Block: "foo"}, NewTemplate(), nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_block/destination/Makefile")
+	s.Equal("# manala:begin foo\nfoo:\n\t@echo foo\n# manala:end\n", string(content))
+}
+
+func (s *SyncBlockTestSuite) TestSyncBlockDestinationWithoutBlock() {
+	err := SyncStrategy("testdata/sync_block/source/Makefile", "testdata/sync_block/destination/Makefile_user", Strategy{Block: "foo"}, NewTemplate(), nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_block/destination/Makefile_user")
+	s.Equal("bar:\n\t@echo bar\n# manala:begin foo\nfoo:\n\t@echo foo\n# manala:end\n", string(content))
+}
+
+func (s *SyncBlockTestSuite) TestSyncBlockDestinationWithBlock() {
+	err := SyncStrategy("testdata/sync_block/source/Makefile", "testdata/sync_block/destination/Makefile_block", Strategy{Block: "foo"}, NewTemplate(), nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_block/destination/Makefile_block")
+	s.Equal("bar:\n\t@echo bar\n\n  # manala:begin foo\nfoo:\n\t@echo foo\n# manala:end\n\nqux:\n\t@echo qux\n", string(content))
+	// Sync again
+	err = SyncStrategy("testdata/sync_block/source/Makefile", "testdata/sync_block/destination/Makefile_block", Strategy{Block: "foo"}, NewTemplate(), nil)
+	s.NoError(err)
+	content2, _ := ioutil.ReadFile("testdata/sync_block/destination/Makefile_block")
+	s.Equal(string(content), string(content2))
+}
+
+func (s *SyncBlockTestSuite) TestSyncBlockDestinationUnterminated() {
+	err := SyncStrategy("testdata/sync_block/source/Makefile", "testdata/sync_block/destination/Makefile_unterminated", Strategy{Block: "foo"}, NewTemplate(), nil)
+	s.Error(err)
+	s.Equal("unterminated block \"foo\" in \"testdata/sync_block/destination/Makefile_unterminated\"", err.Error())
+}
+
+func (s *SyncBlockTestSuite) TestSyncBlockTemplate() {
+	err := SyncStrategy("testdata/sync_block/source/foo.js.tmpl", "testdata/sync_block/destination/foo.js", Strategy{Block: "foo"}, NewTemplate(), map[string]interface{}{
+		"foo": "bar",
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_block/destination/foo.js")
+	s.Equal("// manala:begin foo\nvar foo = \"bar\";\n// manala:end\n", string(content))
+}
+
+/**********************/
+/* Sync Merge - Suite */
+/**********************/
+
+type SyncMergeTestSuite struct{ suite.Suite }
+
+func TestSyncMergeTestSuite(t *testing.T) {
+	// Discard logs
+	log.SetHandler(discard.Default)
+	// Run
+	suite.Run(t, new(SyncMergeTestSuite))
+}
+
+func (s *SyncMergeTestSuite) SetupTest() {
+	dir := "testdata/sync_merge/destination"
+	_ = os.RemoveAll(dir)
+	_ = os.Mkdir(dir, 0755)
+	_ = ioutil.WriteFile(dir+"/compose.yaml", []byte(`# User compose
+services:
+  app:
+    image: php:7.4 # Outdated
+    environment:
+      FOO: bar
+    ports:
+      - 8000:80
+  mailer:
+    image: mailhog
+`), 0666)
+	_ = ioutil.WriteFile(dir+"/composer.json", []byte(`{
+    "name": "foo/bar",
+    "require": {
+        "php": "7.4",
+        "symfony/console": "^5.0"
+    }
+}
+`), 0666)
+	_ = ioutil.WriteFile(dir+"/invalid.yaml", []byte("foo: [bar"), 0666)
+}
+
+/**********************/
+/* Sync Merge - Tests */
+/**********************/
+
+func (s *SyncMergeTestSuite) TestSyncMergeDestinationNotExists() {
+	err := Sync("testdata/sync_merge/source/compose.merge.yaml", "testdata/sync_merge/destination/new.merge.yaml", NewTemplate(), nil)
+	s.NoError(err)
+	s.NoFileExists("testdata/sync_merge/destination/new.merge.yaml")
+	content, _ := ioutil.ReadFile("testdata/sync_merge/destination/new.yaml")
+	source, _ := ioutil.ReadFile("testdata/sync_merge/source/compose.merge.yaml")
+	s.Equal(string(source), string(content))
+}
+
+func (s *SyncMergeTestSuite) TestSyncMergeYaml() {
+	err := Sync("testdata/sync_merge/source/compose.merge.yaml", "testdata/sync_merge/destination/compose.merge.yaml", NewTemplate(), nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_merge/destination/compose.yaml")
+	s.Equal(`# User compose
+services:
+  app:
+    image: php:8.0 # Outdated
+    environment:
+      FOO: bar
+    ports:
+      - 8080:80
+  mailer:
+    image: mailhog
+  db:
+    image: mysql
+`, string(content))
+}
+
+func (s *SyncMergeTestSuite) TestSyncMergeJson() {
+	err := Sync("testdata/sync_merge/source/composer.merge.json.tmpl", "testdata/sync_merge/destination/composer.merge.json.tmpl", NewTemplate(), map[string]interface{}{
+		"php": ">=8.0 <8.2",
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_merge/destination/composer.json")
+	s.Equal(`{
+    "name": "foo/bar",
+    "require": {
+        "php": ">=8.0 <8.2",
+        "symfony/console": "^5.0",
+        "ext-json": "*"
+    },
+    "config": {
+        "sort-packages": true
+    }
+}
+`, string(content))
+}
+
+func (s *SyncMergeTestSuite) TestSyncMergeStrategy() {
+	err := SyncStrategy("testdata/sync_merge/source/compose.merge.yaml", "testdata/sync_merge/destination/compose.yaml", Strategy{Merge: true}, NewTemplate(), nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_merge/destination/compose.yaml")
+	s.Contains(string(content), "  mailer:\n    image: mailhog\n")
+	s.Contains(string(content), "  db:\n    image: mysql\n")
+}
+
+func (s *SyncMergeTestSuite) TestSyncMergeUnsupported() {
+	err := SyncStrategy("testdata/sync_merge/source/foo.txt", "testdata/sync_merge/destination/foo.txt", Strategy{Merge: true}, NewTemplate(), nil)
+	s.Error(err)
+	s.Equal("unable to merge \"testdata/sync_merge/destination/foo.txt\" (unsupported format)", err.Error())
+}
+
+func (s *SyncMergeTestSuite) TestSyncMergeInvalid() {
+	err := SyncStrategy("testdata/sync_merge/source/compose.merge.yaml", "testdata/sync_merge/destination/invalid.yaml", Strategy{Merge: true}, NewTemplate(), nil)
+	s.Error(err)
+	s.Contains(err.Error(), "invalid merge destination \"testdata/sync_merge/destination/invalid.yaml\"")
+}
+
+/**************************/
+/* Sync Normalize - Suite */
+/**************************/
+
+type SyncNormalizeTestSuite struct{ suite.Suite }
+
+func TestSyncNormalizeTestSuite(t *testing.T) {
+	// Discard logs
+	log.SetHandler(discard.Default)
+	// Run
+	suite.Run(t, new(SyncNormalizeTestSuite))
+}
+
+func (s *SyncNormalizeTestSuite) SetupTest() {
+	dir := "testdata/sync_normalize/destination"
+	_ = os.RemoveAll(dir)
+	_ = os.Mkdir(dir, 0755)
+}
+
+/**************************/
+/* Sync Normalize - Tests */
+/**************************/
+
+func (s *SyncNormalizeTestSuite) TestSyncNormalizeNone() {
+	err := SyncStrategy("testdata/sync_normalize/source/foo.tmpl", "testdata/sync_normalize/destination/foo", Strategy{}, NewTemplate(), map[string]interface{}{"foo": "bar"})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_normalize/destination/foo")
+	s.Equal("foo:  \r\n  bar: bar\t\r\nbaz", string(content))
+}
+
+func (s *SyncNormalizeTestSuite) TestSyncNormalizeLf() {
+	err := SyncStrategy("testdata/sync_normalize/source/foo.tmpl", "testdata/sync_normalize/destination/foo", Strategy{
+		Normalization: models.RecipeNormalization{Eol: "lf", TrimTrailingWhitespace: true, FinalNewline: true},
+	}, NewTemplate(), map[string]interface{}{"foo": "bar"})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_normalize/destination/foo")
+	s.Equal("foo:\n  bar: bar\nbaz\n", string(content))
+}
+
+func (s *SyncNormalizeTestSuite) TestSyncNormalizeCrlf() {
+	err := SyncStrategy("testdata/sync_normalize/source/foo.tmpl", "testdata/sync_normalize/destination/foo", Strategy{
+		Normalization: models.RecipeNormalization{Eol: "crlf", FinalNewline: true},
+	}, NewTemplate(), map[string]interface{}{"foo": "bar"})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_normalize/destination/foo")
+	s.Equal("foo:  \r\n  bar: bar\t\r\nbaz\r\n", string(content))
+}
+
+func (s *SyncNormalizeTestSuite) TestSyncNormalizeFinalNewline() {
+	err := SyncStrategy("testdata/sync_normalize/source/foo.tmpl", "testdata/sync_normalize/destination/foo", Strategy{
+		Normalization: models.RecipeNormalization{FinalNewline: true},
+	}, NewTemplate(), map[string]interface{}{"foo": "bar"})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_normalize/destination/foo")
+	s.Equal("foo:  \r\n  bar: bar\t\r\nbaz\r\n", string(content))
+}
+
+func (s *SyncNormalizeTestSuite) TestSyncNormalizeRegular() {
+	err := SyncStrategy("testdata/sync_normalize/source/bar", "testdata/sync_normalize/destination/bar", Strategy{
+		Normalization: models.RecipeNormalization{Eol: "lf", TrimTrailingWhitespace: true, FinalNewline: true},
+	}, NewTemplate(), nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_normalize/destination/bar")
+	s.Equal("foo:  \r\nbar", string(content))
+}
+
 /*************************/
 /* Sync Template - Suite */
 /*************************/
@@ -206,6 +453,63 @@ func (s *SyncTemplateTestSuite) TestSyncTemplateInvalid() {
 	s.Contains(err.Error(), "invalid template")
 }
 
+func (s *SyncTemplateTestSuite) TestSyncTemplateMissingKey() {
+	err := Sync("testdata/sync_template/source/missing_key.tmpl", "testdata/sync_template/destination/missing_key", NewTemplate(), map[string]interface{}{
+		"Vars": map[string]interface{}{
+			"foo":  "foo",
+			"fooz": "fooz",
+			"bar":  map[string]interface{}{"baz": "baz"},
+		},
+	})
+	s.Error(err)
+	var tmplErr *TemplateError
+	s.True(errors.As(err, &tmplErr))
+	s.Equal("testdata/sync_template/source/missing_key.tmpl", tmplErr.Path)
+	s.Equal(2, tmplErr.Line)
+	s.Equal(14, tmplErr.Column)
+	s.Equal("bar: {{ .Vars.fooo }}", tmplErr.Source)
+	s.Equal([]string{".Vars.foo", ".Vars.fooz"}, tmplErr.Suggestions)
+	s.Equal(`invalid template "testdata/sync_template/source/missing_key.tmpl" at line 2, column 14 (at <.Vars.fooo>: map has no entry for key "fooo")
+2 | bar: {{ .Vars.fooo }}
+  |              ^
+did you mean .Vars.foo, .Vars.fooz?`, err.Error())
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateMissingKeyNested() {
+	err := Sync("testdata/sync_template/source/missing_key_nested.tmpl", "testdata/sync_template/destination/missing_key_nested", NewTemplate(), map[string]interface{}{
+		"Vars": map[string]interface{}{
+			"baz": "baz",
+		},
+	})
+	s.Error(err)
+	var tmplErr *TemplateError
+	s.True(errors.As(err, &tmplErr))
+	s.Equal(2, tmplErr.Line)
+	s.Equal([]string{".Vars.baz"}, tmplErr.Suggestions)
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateParseError() {
+	err := Sync("testdata/sync_template/source/parse_error.tmpl", "testdata/sync_template/destination/parse_error", NewTemplate(), nil)
+	s.Error(err)
+	var tmplErr *TemplateError
+	s.True(errors.As(err, &tmplErr))
+	s.Equal(3, tmplErr.Line)
+	s.Equal(0, tmplErr.Column)
+	s.Equal(`invalid template "testdata/sync_template/source/parse_error.tmpl" at line 3 (unexpected "}" in operand)
+3 | bar: {{ .foo }`, err.Error())
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateParseFile() {
+	err := ParseTemplateFile("testdata/sync_template/source/missing_key.tmpl")
+	s.NoError(err)
+
+	err = ParseTemplateFile("testdata/sync_template/source/parse_error.tmpl")
+	s.Error(err)
+	var tmplErr *TemplateError
+	s.True(errors.As(err, &tmplErr))
+	s.Equal(3, tmplErr.Line)
+}
+
 func (s *SyncTemplateTestSuite) TestSyncTemplateToYaml() {
 	err := Sync("testdata/sync_template/source/to_yaml.tmpl", "testdata/sync_template/destination/to_yaml", NewTemplate(), map[string]interface{}{
 		"foo": map[string]interface{}{
@@ -313,3 +617,602 @@ func (s *SyncTemplateTestSuite) TestSyncTemplateHelpers() {
 	content, _ := ioutil.ReadFile("testdata/sync_template/destination/helpers")
 	s.Equal(`bar: foo`, string(content))
 }
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateIsolation() {
+	err := Sync("testdata/sync_template/source/leak", "testdata/sync_template/destination/leak", NewTemplate(), nil)
+	s.Error(err)
+	s.Contains(err.Error(), "invalid template \"testdata/sync_template/source/leak/b.tmpl\"")
+	s.Contains(err.Error(), "no template \"bar\"")
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/leak/a")
+	s.Equal(`bar: baz`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateCollision() {
+	tmpl := NewTemplate()
+	_, _ = tmpl.ParseFiles("testdata/sync_template/source/_helpers.tmpl")
+	err := Sync("testdata/sync_template/source/collision.tmpl", "testdata/sync_template/destination/collision", tmpl, nil)
+	s.Error(err)
+	s.Equal("template \"foo\" defined in \"testdata/sync_template/source/collision.tmpl\" is already defined in \"_helpers.tmpl\"", err.Error())
+	// Base template must be left untouched
+	err = Sync("testdata/sync_template/source/helpers.tmpl", "testdata/sync_template/destination/helpers", tmpl, nil)
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/helpers")
+	s.Equal(`bar: foo`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateFromYaml() {
+	err := Sync("testdata/sync_template/source/from_yaml.tmpl", "testdata/sync_template/destination/from_yaml", NewTemplate(), map[string]interface{}{
+		"foo": "bar:\n  baz: qux\n",
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/from_yaml")
+	s.Equal(`qux
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateFromYamlError() {
+	err := Sync("testdata/sync_template/source/from_yaml.tmpl", "testdata/sync_template/destination/from_yaml", NewTemplate(), map[string]interface{}{
+		"foo": "bar: baz: qux",
+	})
+	s.Error(err)
+	s.Contains(err.Error(), "unable to decode yaml")
+	s.NoFileExists("testdata/sync_template/destination/from_yaml")
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateToJson() {
+	err := Sync("testdata/sync_template/source/to_json.tmpl", "testdata/sync_template/destination/to_json", NewTemplate(), map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": "string",
+			"baz": []interface{}{123, true, nil},
+		},
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/to_json")
+	s.Equal(`{"bar":"string","baz":[123,true,null]}
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateToJsonError() {
+	err := Sync("testdata/sync_template/source/to_json.tmpl", "testdata/sync_template/destination/to_json", NewTemplate(), map[string]interface{}{
+		"foo": func() {},
+	})
+	s.Error(err)
+	s.Contains(err.Error(), "unable to encode json")
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateFromJson() {
+	err := Sync("testdata/sync_template/source/from_json.tmpl", "testdata/sync_template/destination/from_json", NewTemplate(), map[string]interface{}{
+		"foo": `{"bar": {"baz": "qux"}}`,
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/from_json")
+	s.Equal(`qux
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateFromJsonError() {
+	err := Sync("testdata/sync_template/source/from_json.tmpl", "testdata/sync_template/destination/from_json", NewTemplate(), map[string]interface{}{
+		"foo": `{"bar": `,
+	})
+	s.Error(err)
+	s.Contains(err.Error(), "unable to decode json")
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateToToml() {
+	err := Sync("testdata/sync_template/source/to_toml.tmpl", "testdata/sync_template/destination/to_toml", NewTemplate(), map[string]interface{}{
+		"foo": map[string]interface{}{
+			"bar": "string",
+			"baz": map[string]interface{}{
+				"qux": 123,
+			},
+		},
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/to_toml")
+	s.Equal(`bar = "string"
+
+[baz]
+  qux = 123
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateRequired() {
+	err := Sync("testdata/sync_template/source/required.tmpl", "testdata/sync_template/destination/required", NewTemplate(), map[string]interface{}{
+		"foo": "bar",
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/required")
+	s.Equal(`bar
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateRequiredError() {
+	for _, value := range []interface{}{nil, ""} {
+		err := Sync("testdata/sync_template/source/required.tmpl", "testdata/sync_template/destination/required", NewTemplate(), map[string]interface{}{
+			"foo": value,
+		})
+		s.Error(err)
+		s.Contains(err.Error(), "foo is required")
+	}
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateTpl() {
+	err := Sync("testdata/sync_template/source/tpl.tmpl", "testdata/sync_template/destination/tpl", NewTemplate(), map[string]interface{}{
+		"foo": `{{ .bar | upper }}`,
+		"bar": "baz",
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/tpl")
+	s.Equal(`BAZ
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateTplHelpers() {
+	tmpl := NewTemplate()
+	_, _ = tmpl.ParseFiles("testdata/sync_template/source/_helpers.tmpl")
+	err := Sync("testdata/sync_template/source/tpl.tmpl", "testdata/sync_template/destination/tpl", tmpl, map[string]interface{}{
+		"foo": `{{ include "foo" . }}`,
+	})
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_template/destination/tpl")
+	s.Equal(`bar: foo
+`, string(content))
+}
+
+func (s *SyncTemplateTestSuite) TestSyncTemplateTplError() {
+	err := Sync("testdata/sync_template/source/tpl.tmpl", "testdata/sync_template/destination/tpl", NewTemplate(), map[string]interface{}{
+		"foo": `{{ .bar }`,
+	})
+	s.Error(err)
+	s.Contains(err.Error(), "unable to parse template text")
+}
+
+/************************/
+/* Sync Project - Suite */
+/************************/
+
+type SyncProjectTestSuite struct {
+	suite.Suite
+	recipe models.RecipeInterface
+}
+
+func TestSyncProjectTestSuite(t *testing.T) {
+	// Discard logs
+	log.SetHandler(discard.Default)
+	// Run
+	suite.Run(t, new(SyncProjectTestSuite))
+}
+
+func (s *SyncProjectTestSuite) SetupTest() {
+	dir := "testdata/sync_project/destination"
+	_ = os.RemoveAll(dir)
+	_ = os.Mkdir(dir, 0755)
+	s.recipe = models.NewRecipe(
+		"foo",
+		"Foo recipe",
+		"testdata/sync_project/recipe",
+		models.NewRepository(
+			"bar",
+			"testdata/sync_project",
+		),
+	)
+}
+
+/************************/
+/* Sync Project - Tests */
+/************************/
+
+func (s *SyncProjectTestSuite) TestSyncProjectContext() {
+	_ = os.Setenv("MANALA_TEST_FOO", "foo")
+	_ = os.Setenv("MANALA_TEST_BAZ", "baz")
+	defer os.Unsetenv("MANALA_TEST_FOO")
+	defer os.Unsetenv("MANALA_TEST_BAZ")
+
+	s.recipe.AddEnv([]string{"MANALA_TEST_FOO", "MANALA_TEST_BAR"})
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "context.tmpl", Destination: "context"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"foo": "bar"})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/context")
+	s.Equal(`recipe: foo (Foo recipe)
+repository: bar
+commit: 
+project: destination
+manala: 1.2.3
+env: foo, 
+vars: bar
+`, string(content))
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectContextEnvNotAllowed() {
+	_ = os.Setenv("MANALA_TEST_FOO", "foo")
+	defer os.Unsetenv("MANALA_TEST_FOO")
+
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "context.tmpl", Destination: "context"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"foo": "bar"})
+
+	err := SyncProject(prj, "1.2.3")
+	s.Error(err)
+	s.Contains(err.Error(), "map has no entry for key \"MANALA_TEST_FOO\"")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectFiles() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "files.tmpl", Destination: "files"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/files")
+	s.Equal(`foo
+assets/bar: bar
+assets/foo: foo
+`, string(content))
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectFilesOut() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "files_out.tmpl", Destination: "files_out"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+
+	err := SyncProject(prj, "1.2.3")
+	s.Error(err)
+	s.Contains(err.Error(), "file \"../recipe/assets/foo\" out of recipe")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectTemplatedNames() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "names", Destination: "names"},
+		{Source: "assets/foo", Destination: "{{ .Vars.app }}.txt"},
+		{Source: "assets/bar", Destination: "{{ if .Vars.enabled }}bar.txt{{ end }}"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"app": "foo", "enabled": false})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/names/foo.conf")
+	s.Equal(`app: foo
+`, string(content))
+	s.FileExists("testdata/sync_project/destination/names/foo_dir/bar")
+	s.NoFileExists("testdata/sync_project/destination/names/enabled")
+	files, _ := ioutil.ReadDir("testdata/sync_project/destination/names")
+	s.Len(files, 2)
+	s.FileExists("testdata/sync_project/destination/foo.txt")
+	s.NoFileExists("testdata/sync_project/destination/bar.txt")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectTemplatedNamesEnabled() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "names", Destination: "names"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"app": "foo", "enabled": true})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	s.FileExists("testdata/sync_project/destination/names/enabled")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectTemplatedNamesInvalid() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "names", Destination: "names"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"app": "foo/bar", "enabled": false})
+
+	err := SyncProject(prj, "1.2.3")
+	s.Error(err)
+	s.Contains(err.Error(), "invalid templated file name")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectBlock() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "block.tmpl", Destination: ".gitignore", Strategy: "block"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	_ = ioutil.WriteFile("testdata/sync_project/destination/.gitignore", []byte("bar\n"), 0666)
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/.gitignore")
+	s.Equal(`bar
+# manala:begin foo
+foo: destination
+# manala:end
+`, string(content))
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeach() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "vhost.conf.tmpl", Destination: "vhosts/{{ .Item.name }}.conf", Foreach: ".Vars.vhosts"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"vhosts": []interface{}{
+		map[string]interface{}{"name": "foo"},
+		map[string]interface{}{"name": "bar"},
+	}})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/vhosts/foo.conf")
+	s.Equal(`server_name foo; # 0
+`, string(content))
+	content, _ = ioutil.ReadFile("testdata/sync_project/destination/vhosts/bar.conf")
+	s.Equal(`server_name bar; # 1
+`, string(content))
+
+	// Orphans
+	_ = ioutil.WriteFile("testdata/sync_project/destination/vhosts/baz.txt", []byte(""), 0666)
+	prj = models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"vhosts": []interface{}{
+		map[string]interface{}{"name": "foo"},
+	}})
+
+	err = SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	s.FileExists("testdata/sync_project/destination/vhosts/foo.conf")
+	s.NoFileExists("testdata/sync_project/destination/vhosts/bar.conf")
+	s.FileExists("testdata/sync_project/destination/vhosts/baz.txt")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeachOrphans() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "assets/foo", Destination: "{{ .Item }}.yaml", Foreach: ".Vars.items"},
+	})
+
+	// User files, matching destination pattern
+	_ = ioutil.WriteFile("testdata/sync_project/destination/.manala.yaml", []byte("manala: {}\n"), 0666)
+	_ = ioutil.WriteFile("testdata/sync_project/destination/docker-compose.yaml", []byte(""), 0666)
+
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"items": []interface{}{"foo", "bar"}})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	s.FileExists("testdata/sync_project/destination/foo.yaml")
+	s.FileExists("testdata/sync_project/destination/bar.yaml")
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/.manala.sync.yaml")
+	s.Equal(`# Generated by manala, do not edit
+assets/foo {{ .Item }}.yaml:
+    - bar.yaml
+    - foo.yaml
+`, string(content))
+
+	// Only previously generated files are orphans
+	prj = models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"items": []interface{}{"foo"}})
+
+	changes, err := SyncProjectOptions(prj, "1.2.3", Options{DryRun: true})
+	s.NoError(err)
+	s.Equal([]Change{
+		{Path: "testdata/sync_project/destination/bar.yaml", Action: "removed"},
+	}, changes)
+	s.FileExists("testdata/sync_project/destination/bar.yaml")
+
+	err = SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	s.FileExists("testdata/sync_project/destination/foo.yaml")
+	s.NoFileExists("testdata/sync_project/destination/bar.yaml")
+	s.FileExists("testdata/sync_project/destination/.manala.yaml")
+	s.FileExists("testdata/sync_project/destination/docker-compose.yaml")
+
+	// Project files are never orphans, even when recorded
+	_ = ioutil.WriteFile("testdata/sync_project/destination/.manala.sync.yaml", []byte("assets/foo {{ .Item }}.yaml: [foo.yaml, .manala.yaml, ../outside.yaml]\n"), 0666)
+	prj = models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"items": []interface{}{}})
+
+	err = SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	s.NoFileExists("testdata/sync_project/destination/foo.yaml")
+	s.FileExists("testdata/sync_project/destination/.manala.yaml")
+	s.NoFileExists("testdata/sync_project/destination/.manala.sync.yaml")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeachSharedDestination() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "assets/foo", Destination: "{{ .Item }}.yaml", Foreach: ".Vars.foos"},
+		{Source: "assets/bar", Destination: "{{ .Item }}.yaml", Foreach: ".Vars.bars"},
+	})
+
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"foos": []interface{}{"foo"}, "bars": []interface{}{"bar"}})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/.manala.sync.yaml")
+	s.Equal(`# Generated by manala, do not edit
+assets/bar {{ .Item }}.yaml:
+    - bar.yaml
+assets/foo {{ .Item }}.yaml:
+    - foo.yaml
+`, string(content))
+
+	// Each unit own files only are orphans
+	prj = models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"foos": []interface{}{"foo"}, "bars": []interface{}{}})
+
+	changes, err := SyncProjectOptions(prj, "1.2.3", Options{DryRun: true})
+	s.NoError(err)
+	s.Equal([]Change{
+		{Path: "testdata/sync_project/destination/bar.yaml", Action: "removed"},
+	}, changes)
+
+	// Files generated by another unit are not orphans
+	prj = models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"foos": []interface{}{}, "bars": []interface{}{"foo"}})
+
+	changes, err = SyncProjectOptions(prj, "1.2.3", Options{})
+	s.NoError(err)
+	s.Equal([]Change{
+		{Path: "testdata/sync_project/destination/foo.yaml", Action: "updated"},
+		{Path: "testdata/sync_project/destination/bar.yaml", Action: "removed"},
+	}, changes)
+	content, _ = ioutil.ReadFile("testdata/sync_project/destination/foo.yaml")
+	s.Equal("bar", string(content))
+	s.NoFileExists("testdata/sync_project/destination/bar.yaml")
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeachDuplicateUnit() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "assets/foo", Destination: "{{ .Item }}.yaml", Foreach: ".Vars.foos"},
+		{Source: "assets/foo", Destination: "{{ .Item }}.yaml", Foreach: ".Vars.bars"},
+	})
+
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"foos": []interface{}{"foo"}, "bars": []interface{}{"bar"}})
+
+	_, err := SyncProjectOptions(prj, "1.2.3", Options{DryRun: true})
+	s.Error(err)
+	s.Equal(`duplicate foreach unit "assets/foo {{ .Item }}.yaml"`, err.Error())
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeachMap() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "vhost.conf.tmpl", Destination: "vhosts/{{ .Index }}.conf", Foreach: ".Vars.vhosts"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"vhosts": map[string]interface{}{
+		"foo": map[string]interface{}{"name": "foo.com"},
+		"bar": map[string]interface{}{"name": "bar.com"},
+	}})
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/vhosts/foo.conf")
+	s.Equal(`server_name foo.com; # foo
+`, string(content))
+	content, _ = ioutil.ReadFile("testdata/sync_project/destination/vhosts/bar.conf")
+	s.Equal(`server_name bar.com; # bar
+`, string(content))
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeachDuplicate() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "vhost.conf.tmpl", Destination: "vhosts/{{ .Item.name }}.conf", Foreach: ".Vars.vhosts"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"vhosts": []interface{}{
+		map[string]interface{}{"name": "foo"},
+		map[string]interface{}{"name": "foo"},
+	}})
+
+	err := SyncProject(prj, "1.2.3")
+	s.Error(err)
+	s.Equal("duplicate foreach destination \"testdata/sync_project/destination/vhosts/foo.conf\"", err.Error())
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectForeachNotIterable() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "vhost.conf.tmpl", Destination: "vhosts/{{ .Item.name }}.conf", Foreach: ".Vars.vhosts"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"vhosts": "foo"})
+
+	err := SyncProject(prj, "1.2.3")
+	s.Error(err)
+	s.Equal("invalid foreach \".Vars.vhosts\" (string is not iterable)", err.Error())
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectHelpers() {
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "helpers.tmpl", Destination: "helpers"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+
+	err := SyncProject(prj, "1.2.3")
+	s.NoError(err)
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/helpers")
+	s.Equal(`shared, foo, bar
+`, string(content))
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectHelpersCollision() {
+	rec := models.NewRecipe(
+		"foo",
+		"Foo recipe",
+		"testdata/sync_project/recipe_collision",
+		s.recipe.Repository(),
+	)
+	prj := models.NewProject("testdata/sync_project/destination", rec)
+
+	err := SyncProject(prj, "1.2.3")
+	s.Error(err)
+	s.Equal("template \"foo\" defined in \"_b.tmpl\" is already defined in \"_a.tmpl\"", err.Error())
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectDryRun() {
+	_ = ioutil.WriteFile("testdata/sync_project/destination/foo.txt", []byte("baz"), 0666)
+	_ = os.Mkdir("testdata/sync_project/destination/names", 0755)
+	_ = ioutil.WriteFile("testdata/sync_project/destination/names/orphan", []byte("orphan"), 0666)
+
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "names", Destination: "names"},
+		{Source: "assets/foo", Destination: "foo.txt"},
+		{Source: "assets/bar", Destination: "bar.txt"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+	prj.MergeVars(&map[string]interface{}{"app": "foo", "enabled": false})
+
+	expected := []Change{
+		{Path: "testdata/sync_project/destination/names/foo.conf", Action: "created"},
+		{Path: "testdata/sync_project/destination/names/foo_dir", Action: "created"},
+		{Path: "testdata/sync_project/destination/names/foo_dir/bar", Action: "created"},
+		{Path: "testdata/sync_project/destination/names/orphan", Action: "removed"},
+		{Path: "testdata/sync_project/destination/foo.txt", Action: "updated"},
+		{Path: "testdata/sync_project/destination/bar.txt", Action: "created"},
+	}
+
+	changes, err := SyncProjectOptions(prj, "1.2.3", Options{DryRun: true})
+	s.NoError(err)
+	s.Equal(expected, changes)
+
+	// Nothing changed
+	content, _ := ioutil.ReadFile("testdata/sync_project/destination/foo.txt")
+	s.Equal("baz", string(content))
+	s.FileExists("testdata/sync_project/destination/names/orphan")
+	s.NoFileExists("testdata/sync_project/destination/bar.txt")
+	s.NoDirExists("testdata/sync_project/destination/names/foo_dir")
+
+	// Same changes, made
+	changes, err = SyncProjectOptions(prj, "1.2.3", Options{})
+	s.NoError(err)
+	s.Equal(expected, changes)
+
+	content, _ = ioutil.ReadFile("testdata/sync_project/destination/foo.txt")
+	s.Equal("foo", string(content))
+	s.NoFileExists("testdata/sync_project/destination/names/orphan")
+	s.FileExists("testdata/sync_project/destination/bar.txt")
+	s.FileExists("testdata/sync_project/destination/names/foo_dir/bar")
+
+	// Nothing left to change
+	changes, err = SyncProjectOptions(prj, "1.2.3", Options{})
+	s.NoError(err)
+	s.Equal([]Change{}, changes)
+}
+
+func (s *SyncProjectTestSuite) TestSyncProjectLogger() {
+	handler := memory.New()
+
+	s.recipe.AddSyncUnits([]models.RecipeSyncUnit{
+		{Source: "assets/foo", Destination: "foo.txt"},
+	})
+	prj := models.NewProject("testdata/sync_project/destination", s.recipe)
+
+	_, err := SyncProjectOptions(prj, "1.2.3", Options{
+		Logger: &log.Logger{Handler: handler, Level: log.InfoLevel},
+	})
+	s.NoError(err)
+	s.Len(handler.Entries, 1)
+	s.Equal("Synced file", handler.Entries[0].Message)
+	s.Equal("testdata/sync_project/destination/foo.txt", handler.Entries[0].Fields.Get("path"))
+}
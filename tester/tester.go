@@ -0,0 +1,311 @@
+package tester
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/pmezard/go-difflib/difflib"
+	"io"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"manala/syncer"
+	"manala/validator"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Recipe tests directory, holding one directory per case
+var testsDir = "tests"
+
+// Case expected project directory
+var expectedDir = "expected"
+
+// Recipe test case result
+type Result struct {
+	Recipe  string
+	Case    string
+	Err     error    // Case could not be run
+	Diffs   []string // Unified diffs between expected and actual projects
+	Updated bool     // Expected project has been updated
+}
+
+func (result *Result) Passed() bool {
+	return result.Err == nil && len(result.Diffs) == 0
+}
+
+// Run all repository recipes test cases
+func RunRepository(repoLoader loaders.RepositoryLoaderInterface, recLoader loaders.RecipeLoaderInterface, repo models.RepositoryInterface, version string, update bool, logger log.Interface) ([]Result, error) {
+	files, err := ioutil.ReadDir(repo.Dir())
+	if err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+
+	for _, file := range files {
+		// Exclude non recipe files, just like recipe loader does
+		if !file.IsDir() || !loaders.IsRecipeName(file.Name()) {
+			continue
+		}
+		recResults, err := RunRecipe(repoLoader, recLoader, file.Name(), repo, version, update, logger)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, recResults...)
+	}
+
+	return results, nil
+}
+
+// Run a repository recipe test cases.
+// Each case consists of a "tests/<case>/.manala.yaml" project config file,
+// along with its "tests/<case>/expected/" project directory.
+func RunRecipe(repoLoader loaders.RepositoryLoaderInterface, recLoader loaders.RecipeLoaderInterface, name string, repo models.RepositoryInterface, version string, update bool, logger log.Interface) ([]Result, error) {
+	// Cases projects are forced to use the local recipe
+	prjLoader := loaders.NewProjectLoader(repoLoader, recLoader, repo.Src(), name, nil, nil, logger)
+
+	dir := filepath.Join(repo.Dir(), name, testsDir)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	results := []Result{}
+
+	for _, file := range files {
+		if !file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		prjFile, err := prjLoader.Find(filepath.Join(dir, file.Name()), false)
+		if err != nil {
+			return nil, err
+		}
+		if prjFile == nil {
+			continue
+		}
+
+		logger.WithFields(log.Fields{
+			"recipe": name,
+			"case":   file.Name(),
+		}).Debug("Running recipe test case...")
+
+		result := runCase(prjLoader, prjFile, version, update, logger)
+		result.Recipe = name
+		result.Case = file.Name()
+
+		_ = prjFile.Close()
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func runCase(prjLoader loaders.ProjectLoaderInterface, prjFile *os.File, version string, update bool, logger log.Interface) Result {
+	result := Result{}
+
+	prj, err := prjLoader.Load(prjFile)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if err := validator.ValidateProject(prj); err != nil {
+		result.Err = err
+		return result
+	}
+
+	// Sync into a temporary directory, named after the case,
+	// so that project name remains the same from one run to another
+	tmpDir, err := ioutil.TempDir("", "manala-test")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caseDir := filepath.Dir(prjFile.Name())
+	dir := filepath.Join(tmpDir, filepath.Base(caseDir))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		result.Err = err
+		return result
+	}
+
+	casePrj := models.NewProject(dir, prj.Recipe())
+	vars := prj.Vars()
+	casePrj.MergeVars(&vars)
+
+	if _, err := syncer.SyncProjectOptions(casePrj, version, syncer.Options{Logger: logger}); err != nil {
+		result.Err = err
+		return result
+	}
+
+	expected := filepath.Join(caseDir, expectedDir)
+
+	if update {
+		if err := os.RemoveAll(expected); err != nil {
+			result.Err = err
+			return result
+		}
+		if err := copyTree(dir, expected); err != nil {
+			result.Err = err
+			return result
+		}
+		result.Updated = true
+		return result
+	}
+
+	result.Diffs, result.Err = diffTrees(expected, dir)
+
+	return result
+}
+
+// Get unified diffs between expected and actual directories files
+func diffTrees(expected string, actual string) ([]string, error) {
+	expectedFiles, err := treeFiles(expected)
+	if err != nil {
+		return nil, err
+	}
+	actualFiles, err := treeFiles(actual)
+	if err != nil {
+		return nil, err
+	}
+
+	// Union of both trees files, sorted
+	filesMap := map[string]bool{}
+	for file := range expectedFiles {
+		filesMap[file] = true
+	}
+	for file := range actualFiles {
+		filesMap[file] = true
+	}
+	var files []string
+	for file := range filesMap {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var diffs []string
+
+	for _, file := range files {
+		var expectedContent, actualContent []byte
+
+		if expectedFiles[file] {
+			if expectedContent, err = ioutil.ReadFile(filepath.Join(expected, file)); err != nil {
+				return nil, err
+			}
+		}
+		if actualFiles[file] {
+			if actualContent, err = ioutil.ReadFile(filepath.Join(actual, file)); err != nil {
+				return nil, err
+			}
+		}
+
+		if expectedFiles[file] && actualFiles[file] && bytes.Equal(expectedContent, actualContent) {
+			continue
+		}
+
+		fromFile, toFile := "expected/"+file, "actual/"+file
+		if !expectedFiles[file] {
+			fromFile = "/dev/null"
+		}
+		if !actualFiles[file] {
+			toFile = "/dev/null"
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(expectedContent),
+			B:        splitLines(actualContent),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// Split content into lines, keeping their new lines, and flagging a missing final one
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n\\ No newline at end of file\n"
+	}
+
+	return lines
+}
+
+// Get a directory files, relative to it, and slash separated
+func treeFiles(dir string) (map[string]bool, error) {
+	files := map[string]bool{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Non existing directory has no files
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+
+	return files, err
+}
+
+// Copy a directory tree, keeping files modes
+func copyTree(src string, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		srcFile, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer srcFile.Close()
+
+		dstFile, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+		if err != nil {
+			return err
+		}
+		defer dstFile.Close()
+
+		_, err = io.Copy(dstFile, srcFile)
+
+		return err
+	})
+}
@@ -0,0 +1,132 @@
+package tester
+
+import (
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"manala/loaders"
+	"manala/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/****************/
+/* Test - Suite */
+/****************/
+
+type TestTestSuite struct {
+	suite.Suite
+	repoLoader loaders.RepositoryLoaderInterface
+	recLoader  loaders.RecipeLoaderInterface
+	repository models.RepositoryInterface
+	logger     log.Interface
+}
+
+func TestTestTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(TestTestSuite))
+}
+
+func (s *TestTestSuite) SetupTest() {
+	s.repoLoader = loaders.NewRepositoryLoader("", "", log.Log)
+	s.recLoader = loaders.NewRecipeLoader("", log.Log)
+	s.repository, _ = s.repoLoader.Load("testdata/repository")
+	s.logger = &log.Logger{Handler: discard.Default}
+}
+
+/****************/
+/* Test - Tests */
+/****************/
+
+func (s *TestTestSuite) TestRunRecipe() {
+	results, err := RunRecipe(s.repoLoader, s.recLoader, "foo", s.repository, "", false, s.logger)
+	s.NoError(err)
+	s.Len(results, 3)
+
+	s.Equal("foo", results[0].Recipe)
+	s.Equal("custom", results[0].Case)
+	s.False(results[0].Passed())
+	s.NoError(results[0].Err)
+	s.Equal([]string{
+		`--- expected/file
++++ actual/file
+@@ -1,2 +1,2 @@
+-foo: qux
++foo: baz
+ name: custom
+`,
+		`--- expected/orphan
++++ /dev/null
+@@ -1 +0,0 @@
+-orphan
+`,
+	}, results[0].Diffs)
+
+	s.Equal("default", results[1].Case)
+	s.True(results[1].Passed())
+
+	s.Equal("invalid", results[2].Case)
+	s.False(results[2].Passed())
+	s.Error(results[2].Err)
+	s.Equal("project config errors:\n- foo: Invalid type. Expected: string, given: array", results[2].Err.Error())
+}
+
+func (s *TestTestSuite) TestRunRecipeWithoutTests() {
+	results, err := RunRecipe(s.repoLoader, s.recLoader, "bar", s.repository, "", false, s.logger)
+	s.NoError(err)
+	s.Empty(results)
+}
+
+func (s *TestTestSuite) TestRunRepository() {
+	results, err := RunRepository(s.repoLoader, s.recLoader, s.repository, "", false, s.logger)
+	s.NoError(err)
+	s.Len(results, 3)
+}
+
+func (s *TestTestSuite) TestRunRecipeUpdate() {
+	// Work on a repository copy
+	dir, _ := ioutil.TempDir("", "manala-test-update")
+	defer os.RemoveAll(dir)
+	s.NoError(copyTree("testdata/repository", dir))
+	repo, _ := s.repoLoader.Load(dir)
+
+	results, err := RunRecipe(s.repoLoader, s.recLoader, "foo", repo, "", true, s.logger)
+	s.NoError(err)
+	s.Len(results, 3)
+	s.True(results[0].Updated)
+	s.True(results[1].Updated)
+	s.False(results[2].Updated)
+
+	s.NoFileExists(filepath.Join(dir, "foo/tests/custom/expected/orphan"))
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "foo/tests/custom/expected/file"))
+	s.Equal("foo: baz\nname: custom\n", string(content))
+
+	// Run again
+	results, err = RunRecipe(s.repoLoader, s.recLoader, "foo", repo, "", false, s.logger)
+	s.NoError(err)
+	s.True(results[0].Passed())
+	s.True(results[1].Passed())
+}
+
+func (s *TestTestSuite) TestDiffTreesFinalNewLine() {
+	dir, _ := ioutil.TempDir("", "manala-test-diff")
+	defer os.RemoveAll(dir)
+	_ = os.MkdirAll(filepath.Join(dir, "expected"), 0755)
+	_ = os.MkdirAll(filepath.Join(dir, "actual"), 0755)
+	_ = ioutil.WriteFile(filepath.Join(dir, "expected/file"), []byte("foo\n"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "actual/file"), []byte("foo"), 0644)
+
+	diffs, err := diffTrees(filepath.Join(dir, "expected"), filepath.Join(dir, "actual"))
+	s.NoError(err)
+	s.Equal([]string{
+		`--- expected/file
++++ actual/file
+@@ -1 +1 @@
+-foo
++foo
+\ No newline at end of file
+`,
+	}, diffs)
+}
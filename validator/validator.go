@@ -3,8 +3,11 @@ package validator
 import (
 	"github.com/mingrammer/commonregex"
 	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
 	"manala/models"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 func ValidateValue(value interface{}, schema map[string]interface{}) error {
@@ -65,6 +68,212 @@ func (err *ProjectValidationError) Error() string {
 	return str
 }
 
+// Get violations, located in the last project config layer defining their values.
+// Values not fully defined by any layer are located in the layer defining most of
+// their path, first layer (main project config) winning on ties.
+func (err *ProjectValidationError) Violations(layers ...models.ProjectLayer) []ProjectViolation {
+	var violations []ProjectViolation
+
+	for _, e := range err.Errors {
+		// Split context on a delimiter unlikely to be found in keys
+		var path []string
+		for _, key := range strings.Split(e.Context().String("\x00"), "\x00") {
+			if key != gojsonschema.STRING_CONTEXT_ROOT {
+				path = append(path, key)
+			}
+		}
+
+		// Point to the offending property itself
+		if e.Type() == "additional_property_not_allowed" {
+			if property, ok := e.Details()["property"].(string); ok {
+				path = append(path, property)
+			}
+		}
+
+		violation := ProjectViolation{
+			Pointer: jsonPointer(path),
+			Keyword: resultErrorKeyword(e),
+			Message: e.Description(),
+		}
+
+		if layer := locateLayer(layers, path); layer != nil {
+			violation.File = layer.File
+			// Layers without files, such as set values, have no location
+			if layer.File != "" && layer.Node != nil {
+				violation.Line, violation.Column, _ = locateNode(layer.Node, path)
+			}
+		}
+
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+// Project validation violation
+type ProjectViolation struct {
+	File    string `json:"file,omitempty"`
+	Pointer string `json:"pointer"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// Violated schema keywords, indexed by result error types
+var resultErrorKeywords = map[string]string{
+	"additional_property_not_allowed": "additionalProperties",
+	"array_contains":                  "contains",
+	"array_max_items":                 "maxItems",
+	"array_max_properties":            "maxProperties",
+	"array_min_items":                 "minItems",
+	"array_min_properties":            "minProperties",
+	"array_no_additional_items":       "additionalItems",
+	"condition_else":                  "else",
+	"condition_then":                  "then",
+	"const":                           "const",
+	"does_not_match_pattern":          "pattern",
+	"enum":                            "enum",
+	"format":                          "format",
+	"invalid_property_name":           "propertyNames",
+	"invalid_property_pattern":        "patternProperties",
+	"invalid_type":                    "type",
+	"missing_dependency":              "dependencies",
+	"multiple_of":                     "multipleOf",
+	"number_all_of":                   "allOf",
+	"number_any_of":                   "anyOf",
+	"number_gt":                       "exclusiveMinimum",
+	"number_gte":                      "minimum",
+	"number_lt":                       "exclusiveMaximum",
+	"number_lte":                      "maximum",
+	"number_not":                      "not",
+	"number_one_of":                   "oneOf",
+	"required":                        "required",
+	"string_gte":                      "minLength",
+	"string_lte":                      "maxLength",
+	"unique":                          "uniqueItems",
+}
+
+func resultErrorKeyword(e gojsonschema.ResultError) string {
+	if keyword, ok := resultErrorKeywords[e.Type()]; ok {
+		return keyword
+	}
+	return e.Type()
+}
+
+// See: https://tools.ietf.org/html/rfc6901
+func jsonPointer(path []string) string {
+	pointer := ""
+	for _, key := range path {
+		pointer += "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+	}
+	return pointer
+}
+
+// Get the layer defining a value path
+func locateLayer(layers []models.ProjectLayer, path []string) *models.ProjectLayer {
+	var layer *models.ProjectLayer
+	depth := -1
+
+	for i := range layers {
+		if layers[i].Node == nil {
+			continue
+		}
+		_, _, layerDepth := locateNode(layers[i].Node, path)
+		// Later layers override earlier ones, but only on fully defined values
+		if layerDepth > depth || (layerDepth == depth && depth == len(path)) {
+			layer, depth = &layers[i], layerDepth
+		}
+	}
+
+	return layer
+}
+
+// Get line and column of the deepest node found along path, along with its depth
+func locateNode(node *yaml.Node, path []string) (int, int, int) {
+	depth := 0
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line, column := node.Line, node.Column
+
+	for _, key := range path {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					// Locate mapping values on their keys
+					line, column = node.Content[i].Line, node.Content[i].Column
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line, column = next.Line, next.Column
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		node = next
+		depth++
+	}
+
+	return line, column, depth
+}
+
+// Get a standalone (draft-07) project config schema, based on recipe one
+func ProjectSchema(rec models.RecipeInterface) map[string]interface{} {
+	schema := map[string]interface{}{}
+	for key, value := range rec.Schema() {
+		schema[key] = value
+	}
+
+	properties := map[string]interface{}{}
+	if recProperties, ok := schema["properties"].(map[string]interface{}); ok {
+		for key, value := range recProperties {
+			properties[key] = value
+		}
+	}
+
+	// Manala config
+	properties["manala"] = map[string]interface{}{
+		"type":                 "object",
+		"description":          "Manala config",
+		"additionalProperties": false,
+		"required":             []interface{}{"recipe"},
+		"properties": map[string]interface{}{
+			"recipe": map[string]interface{}{
+				"type":        "string",
+				"description": "Recipe name",
+				"default":     rec.Name(),
+			},
+			"repository": map[string]interface{}{
+				"type":        "string",
+				"description": "Recipe repository source",
+			},
+		},
+	}
+
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = rec.Name()
+	schema["description"] = rec.Description()
+	schema["type"] = "object"
+	schema["properties"] = properties
+
+	return schema
+}
+
 /**************************/
 /* Custom Format Checkers */
 /**************************/
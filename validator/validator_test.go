@@ -2,6 +2,8 @@ package validator
 
 import (
 	"github.com/stretchr/testify/suite"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
 	"manala/models"
 	"testing"
 )
@@ -114,6 +116,145 @@ func (s *ValidateProjectTestSuite) TestValidateProjectError() {
 	s.Equal("project config errors:\n- foo: Invalid type. Expected: string, given: integer", err.Error())
 }
 
+func (s *ValidateProjectTestSuite) TestValidateProjectViolations() {
+	s.project.Recipe().MergeSchema(
+		&map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"foo": map[string]interface{}{
+					"type": "string",
+				},
+				"bar": map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"baz"},
+					"properties": map[string]interface{}{
+						"baz": map[string]interface{}{"type": "string"},
+						"qux": map[string]interface{}{
+							"type":  "array",
+							"items": map[string]interface{}{"enum": []interface{}{"foo"}},
+						},
+					},
+				},
+			},
+		},
+	)
+	s.project.MergeVars(
+		&map[string]interface{}{
+			"foo":   123,
+			"bar":   map[string]interface{}{"qux": []interface{}{"foo", "bar"}},
+			"a/b~c": true,
+		},
+	)
+	err := ValidateProject(s.project)
+	s.Error(err)
+
+	node := yaml.Node{}
+	_ = yaml.Unmarshal([]byte(`foo: 123
+bar:
+    qux:
+      - foo
+      - bar
+a/b~c: true
+`), &node)
+
+	violations := err.(*ProjectValidationError).Violations(models.ProjectLayer{File: "foo.yaml", Node: &node})
+	s.ElementsMatch([]ProjectViolation{
+		{File: "foo.yaml", Pointer: "/a~1b~0c", Keyword: "additionalProperties", Message: "Additional property a/b~c is not allowed", Line: 6, Column: 1},
+		{File: "foo.yaml", Pointer: "/foo", Keyword: "type", Message: "Invalid type. Expected: string, given: integer", Line: 1, Column: 1},
+		{File: "foo.yaml", Pointer: "/bar", Keyword: "required", Message: "baz is required", Line: 2, Column: 1},
+		{File: "foo.yaml", Pointer: "/bar/qux/1", Keyword: "enum", Message: "bar.qux.1 must be one of the following: \"foo\"", Line: 5, Column: 9},
+	}, violations)
+
+	// Without layers, violations are not located
+	violations = err.(*ProjectValidationError).Violations()
+	s.Len(violations, 4)
+	for _, violation := range violations {
+		s.Equal("", violation.File)
+		s.Equal(0, violation.Line)
+	}
+}
+
+func (s *ValidateProjectTestSuite) TestValidateProjectViolationsLayers() {
+	s.project.Recipe().MergeSchema(
+		&map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"foo": map[string]interface{}{"type": "string"},
+				"bar": map[string]interface{}{"type": "string"},
+				"baz": map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"qux"},
+				},
+			},
+		},
+	)
+	s.project.MergeVars(
+		&map[string]interface{}{
+			"foo": 123,
+			"bar": 456,
+			"baz": map[string]interface{}{},
+		},
+	)
+	err := ValidateProject(s.project)
+	s.Error(err)
+
+	mainNode := yaml.Node{}
+	_ = yaml.Unmarshal([]byte("foo: foo\nbar: bar\nbaz: {}\n"), &mainNode)
+	valuesNode := yaml.Node{}
+	_ = yaml.Unmarshal([]byte("# Values\nfoo: 123\n"), &valuesNode)
+	setNode := yaml.Node{}
+	_ = setNode.Encode(map[string]interface{}{"bar": 456})
+
+	violations := err.(*ProjectValidationError).Violations(
+		models.ProjectLayer{File: ".manala.yaml", Node: &mainNode},
+		models.ProjectLayer{File: "values.yaml", Node: &valuesNode},
+		models.ProjectLayer{Node: &setNode},
+	)
+	s.ElementsMatch([]ProjectViolation{
+		{File: "values.yaml", Pointer: "/foo", Keyword: "type", Message: "Invalid type. Expected: string, given: integer", Line: 2, Column: 1},
+		{Pointer: "/bar", Keyword: "type", Message: "Invalid type. Expected: string, given: integer"},
+		{File: ".manala.yaml", Pointer: "/baz", Keyword: "required", Message: "qux is required", Line: 3, Column: 1},
+	}, violations)
+}
+
+func (s *ValidateProjectTestSuite) TestProjectSchema() {
+	s.project.Recipe().MergeSchema(
+		&map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"foo": map[string]interface{}{
+					"type":        "string",
+					"description": "Foo",
+				},
+			},
+		},
+	)
+	schema := ProjectSchema(s.project.Recipe())
+	s.Equal("http://json-schema.org/draft-07/schema#", schema["$schema"])
+	s.Equal("foo", schema["title"])
+	s.Equal("bar", schema["description"])
+	s.Equal(false, schema["additionalProperties"])
+	properties := schema["properties"].(map[string]interface{})
+	s.Equal(map[string]interface{}{"type": "string", "description": "Foo"}, properties["foo"])
+	s.Contains(properties, "manala")
+	// Recipe schema must be left untouched
+	s.NotContains(s.project.Recipe().Schema()["properties"], "manala")
+	s.NotContains(s.project.Recipe().Schema(), "$schema")
+
+	// Project config must validate against schema
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewGoLoader(schema),
+		gojsonschema.NewGoLoader(map[string]interface{}{
+			"manala": map[string]interface{}{"recipe": "foo"},
+			"foo":    "bar",
+		}),
+	)
+	s.NoError(err)
+	s.True(result.Valid())
+}
+
 /**************************/
 /* Format Checker - Suite */
 /**************************/
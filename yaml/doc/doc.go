@@ -2,6 +2,7 @@ package doc
 
 import (
 	"regexp"
+	"strings"
 )
 
 var regex, _ = regexp.Compile(
@@ -84,3 +85,24 @@ func ParseCommentTags(comment string) TagList {
 
 	return list
 }
+
+// Parse comment description, made of the lines preceding the first tag
+func ParseCommentDescription(comment string) string {
+	var lines []string
+
+	for _, submatch := range regex.FindAllStringSubmatch(comment, -1) {
+		for i, match := range submatch {
+			if i == 0 || match == "" {
+				continue
+			}
+			switch regexGroups[i] {
+			case "Tag":
+				return strings.Join(lines, "\n")
+			case "String":
+				lines = append(lines, strings.TrimSpace(match))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
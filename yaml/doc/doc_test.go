@@ -37,6 +37,19 @@ func (s *ParseTestSuite) TestParseCommentTags() {
 	)
 }
 
+func (s *ParseTestSuite) TestParseCommentDescription() {
+	description := ParseCommentDescription(`
+	  # Foo bar
+	  #   baz
+	  # @foo bar
+	  # qux
+	`)
+	s.Equal("Foo bar\nbaz", description)
+
+	s.Equal("", ParseCommentDescription(`# @foo bar`))
+	s.Equal("Foo", ParseCommentDescription(`# Foo`))
+}
+
 /****************/
 /* List - Suite */
 /****************/
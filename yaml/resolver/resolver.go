@@ -0,0 +1,233 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/apex/log"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Masked value, logged in place of secrets
+const Mask = "******"
+
+// Resolve env vars interpolations, as well as secrets and files tags, in yaml nodes.
+//
+// Env vars are looked up in environment, then, as secrets, in secrets map.
+// Files paths are relative to dir.
+func NewResolver(dir string, lookupEnv func(key string) (string, bool), secrets map[string]string, logger log.Interface) *Resolver {
+	return &Resolver{
+		dir:       dir,
+		lookupEnv: lookupEnv,
+		secrets:   secrets,
+		logger:    logger,
+	}
+}
+
+type Resolver struct {
+	dir       string
+	lookupEnv func(key string) (string, bool)
+	secrets   map[string]string
+	logger    log.Interface
+}
+
+// Resolve node, and its children, in place
+func (r *Resolver) Resolve(node *yaml.Node) error {
+	return r.resolveNode(node, "")
+}
+
+func (r *Resolver) resolveNode(node *yaml.Node, path string) error {
+	// Secrets and files tags only apply on scalars
+	if (node.Tag == "!secret" || node.Tag == "!file") && node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("invalid %s tag at \"%s\" (line %d)", node.Tag, path, node.Line)
+	}
+
+	// Aliases are resolved where their anchors are defined
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := r.resolveNode(child, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := r.resolveNode(node.Content[i+1], path+"/"+node.Content[i].Value); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if err := r.resolveNode(child, path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return r.resolveScalar(node, path)
+	}
+
+	return nil
+}
+
+func (r *Resolver) resolveScalar(node *yaml.Node, path string) error {
+	switch node.Tag {
+	case "!secret":
+		value, ok := r.secrets[node.Value]
+		if !ok {
+			return fmt.Errorf("unresolved secret \"%s\" at \"%s\" (line %d)", node.Value, path, node.Line)
+		}
+		r.logger.WithFields(log.Fields{"path": path, "secret": node.Value, "value": Mask}).Debug("Resolving secret...")
+		setString(node, value)
+	case "!file":
+		file := node.Value
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(r.dir, file)
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("unresolved file \"%s\" at \"%s\" (line %d)", node.Value, path, node.Line)
+			}
+			return err
+		}
+		r.logger.WithFields(log.Fields{"path": path, "file": node.Value, "value": Mask}).Debug("Resolving file...")
+		setString(node, strings.TrimRight(string(content), "\r\n"))
+	default:
+		// Only interpolate strings
+		if node.ShortTag() != "!!str" || !strings.Contains(node.Value, "$") {
+			return nil
+		}
+		value, err := r.interpolate(node.Value, path)
+		if err != nil {
+			return fmt.Errorf("%w at \"%s\" (line %d)", err, path, node.Line)
+		}
+		node.Value = value
+		// Let plain scalars type be inferred from their interpolated value
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	}
+
+	return nil
+}
+
+// Secrets and files values are always strings
+func setString(node *yaml.Node, value string) {
+	node.Tag = "!!str"
+	node.Value = value
+	node.Style = yaml.DoubleQuotedStyle
+}
+
+var envVarRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Interpolate "${VAR}" and "${VAR:-default}" env vars, "$$" escaping a "$"
+func (r *Resolver) interpolate(value string, path string) (string, error) {
+	var buf strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			buf.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			buf.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("invalid interpolation \"%s\"", value[i:])
+			}
+			expr := value[i+2 : i+end]
+
+			name, def, hasDef := expr, "", false
+			if j := strings.Index(expr, ":-"); j >= 0 {
+				name, def, hasDef = expr[:j], expr[j+2:], true
+			}
+			if !envVarRegex.MatchString(name) {
+				return "", fmt.Errorf("invalid interpolation \"%s\"", value[i:i+end+1])
+			}
+
+			// Env vars usually hold tokens and passwords, so that their values are masked too
+			envValue, ok := r.lookupEnv(name)
+			if !ok {
+				envValue, ok = r.secrets[name]
+			}
+			if ok {
+				r.logger.WithFields(log.Fields{"path": path, "var": name, "value": Mask}).Debug("Interpolating env var...")
+			}
+
+			// Default applies on both unset and empty env vars
+			if envValue == "" && hasDef {
+				envValue, ok = def, true
+			}
+			if !ok {
+				return "", fmt.Errorf("unresolved env var \"%s\"", name)
+			}
+
+			buf.WriteString(envValue)
+			i += end
+		default:
+			buf.WriteByte('$')
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// Parse dotenv content, made of "KEY=value" lines, optionally exported,
+// values being possibly quoted, and "#" comments
+func ParseDotenv(content []byte) (map[string]string, error) {
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+		i := strings.IndexByte(text, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid dotenv (line %d)", line)
+		}
+
+		key, value := strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		if !envVarRegex.MatchString(key) {
+			return nil, fmt.Errorf("invalid dotenv key \"%s\" (line %d)", key, line)
+		}
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			if value[0] == '"' {
+				unquoted, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid dotenv value \"%s\" (line %d)", key, line)
+				}
+				value = unquoted
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		} else if j := strings.Index(value, " #"); j >= 0 {
+			// Inline comments only apply on unquoted values
+			value = strings.TrimSpace(value[:j])
+		}
+
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
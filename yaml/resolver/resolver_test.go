@@ -0,0 +1,175 @@
+package resolver
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/json"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+/*******************/
+/* Resolve - Suite */
+/*******************/
+
+type ResolveTestSuite struct {
+	suite.Suite
+	resolver *Resolver
+}
+
+func TestResolveTestSuite(t *testing.T) {
+	// Run
+	suite.Run(t, new(ResolveTestSuite))
+}
+
+func (s *ResolveTestSuite) SetupTest() {
+	env := map[string]string{
+		"FOO":   "foo",
+		"EMPTY": "",
+		"PORT":  "8080",
+	}
+	s.resolver = NewResolver(
+		"testdata",
+		func(key string) (string, bool) {
+			value, ok := env[key]
+			return value, ok
+		},
+		map[string]string{
+			"password": "s3cr3t",
+			"TOKEN":    "t0k3n",
+		},
+		log.Log,
+	)
+}
+
+func (s *ResolveTestSuite) resolve(content string) (interface{}, error) {
+	node := yaml.Node{}
+	_ = yaml.Unmarshal([]byte(content), &node)
+	if err := s.resolver.Resolve(&node); err != nil {
+		return nil, err
+	}
+	var value interface{}
+	_ = node.Decode(&value)
+	return value, nil
+}
+
+/*******************/
+/* Resolve - Tests */
+/*******************/
+
+func (s *ResolveTestSuite) TestResolve() {
+	for _, t := range []struct {
+		test     string
+		content  string
+		expected interface{}
+	}{
+		{test: "Env var", content: `foo: ${FOO}`, expected: map[string]interface{}{"foo": "foo"}},
+		{test: "Env var in string", content: `foo: bar-${FOO}-baz`, expected: map[string]interface{}{"foo": "bar-foo-baz"}},
+		{test: "Env var default", content: `foo: ${BAR:-bar}`, expected: map[string]interface{}{"foo": "bar"}},
+		{test: "Env var default empty", content: `foo: ${EMPTY:-bar}`, expected: map[string]interface{}{"foo": "bar"}},
+		{test: "Env var empty", content: `foo: "${EMPTY}"`, expected: map[string]interface{}{"foo": ""}},
+		{test: "Env var typed", content: `foo: ${PORT}`, expected: map[string]interface{}{"foo": 8080}},
+		{test: "Env var quoted", content: `foo: "${PORT}"`, expected: map[string]interface{}{"foo": "8080"}},
+		{test: "Env var secret", content: `foo: ${TOKEN}`, expected: map[string]interface{}{"foo": "t0k3n"}},
+		{test: "Escaped", content: `foo: $${FOO} $bar`, expected: map[string]interface{}{"foo": "${FOO} $bar"}},
+		{test: "Sequence", content: `foo: [bar, "${FOO}"]`, expected: map[string]interface{}{"foo": []interface{}{"bar", "foo"}}},
+		{test: "Secret", content: `foo: !secret password`, expected: map[string]interface{}{"foo": "s3cr3t"}},
+		{test: "File", content: `foo: !file file`, expected: map[string]interface{}{"foo": "foo\nbar"}},
+		{test: "Key", content: `${FOO}: bar`, expected: map[string]interface{}{"${FOO}": "bar"}},
+	} {
+		s.Run(t.test, func() {
+			value, err := s.resolve(t.content)
+			s.NoError(err)
+			s.Equal(t.expected, value)
+		})
+	}
+}
+
+func (s *ResolveTestSuite) TestResolveErrors() {
+	for _, t := range []struct {
+		test    string
+		content string
+		err     string
+	}{
+		{
+			test:    "Env var unresolved",
+			content: "foo:\n  bar: ${BAR}",
+			err:     "unresolved env var \"BAR\" at \"/foo/bar\" (line 2)",
+		},
+		{
+			test:    "Env var unterminated",
+			content: `foo: ${FOO`,
+			err:     "invalid interpolation \"${FOO\" at \"/foo\" (line 1)",
+		},
+		{
+			test:    "Env var invalid",
+			content: `foo: ${F-O}`,
+			err:     "invalid interpolation \"${F-O}\" at \"/foo\" (line 1)",
+		},
+		{
+			test:    "Secret unresolved",
+			content: "foo: [bar, !secret baz]",
+			err:     "unresolved secret \"baz\" at \"/foo/1\" (line 1)",
+		},
+		{
+			test:    "Secret invalid",
+			content: "foo: !secret {bar: baz}",
+			err:     "invalid !secret tag at \"/foo\" (line 1)",
+		},
+		{
+			test:    "File unresolved",
+			content: "foo: !file not_found",
+			err:     "unresolved file \"not_found\" at \"/foo\" (line 1)",
+		},
+	} {
+		s.Run(t.test, func() {
+			_, err := s.resolve(t.content)
+			s.Error(err)
+			s.Equal(t.err, err.Error())
+		})
+	}
+}
+
+func (s *ResolveTestSuite) TestResolveMaskedLogs() {
+	var buf bytes.Buffer
+	log.SetHandler(json.New(&buf))
+	log.SetLevel(log.DebugLevel)
+	defer log.SetLevel(log.InfoLevel)
+
+	_, err := s.resolve("foo: !secret password\nbar: ${TOKEN}\nbaz: !file file\nqux: ${FOO}")
+	s.NoError(err)
+	s.NotContains(buf.String(), "s3cr3t")
+	s.NotContains(buf.String(), "t0k3n")
+	s.NotContains(buf.String(), `foo\nbar`)
+	s.NotContains(buf.String(), `"value":"foo"`)
+	s.Contains(buf.String(), Mask)
+	s.Contains(buf.String(), `"var":"FOO"`)
+}
+
+func (s *ResolveTestSuite) TestParseDotenv() {
+	values, err := ParseDotenv([]byte(`# Comment
+FOO=foo
+export BAR = bar # Comment
+
+BAZ="baz # Not a comment\n"
+QUX='qux'
+EMPTY=
+`))
+	s.NoError(err)
+	s.Equal(map[string]string{
+		"FOO":   "foo",
+		"BAR":   "bar",
+		"BAZ":   "baz # Not a comment\n",
+		"QUX":   "qux",
+		"EMPTY": "",
+	}, values)
+
+	_, err = ParseDotenv([]byte("FOO=foo\nBAR"))
+	s.Error(err)
+	s.Equal("invalid dotenv (line 2)", err.Error())
+
+	_, err = ParseDotenv([]byte("F-O=foo"))
+	s.Error(err)
+	s.Equal("invalid dotenv key \"F-O\" (line 1)", err.Error())
+}